@@ -0,0 +1,86 @@
+package chatwork
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL Chatwork API v2のエンドポイント
+const DefaultBaseURL = "https://api.chatwork.com/v2"
+
+// Client Chatworkへメッセージを投稿するクライアント
+// 本番ではAPIClient、テストやローカル開発ではFakeServerに向けたAPIClientを使う
+type Client interface {
+	PostMessage(ctx context.Context, roomID, body string) (messageID string, err error)
+}
+
+// APIError Chatwork APIがエラーを返した場合のエラー
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Chatwork APIエラー - Status: %d, Body: %s", e.StatusCode, e.Body)
+}
+
+// APIClient Chatwork APIを呼び出すClientの実装
+type APIClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewAPIClient APIクライアントを作成（baseURLが空の場合はDefaultBaseURLを使う）
+func NewAPIClient(baseURL, token string) *APIClient {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &APIClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// PostMessage ルームにメッセージを投稿し、投稿されたメッセージのIDを返す
+func (c *APIClient) PostMessage(ctx context.Context, roomID, body string) (string, error) {
+	endpoint := fmt.Sprintf("%s/rooms/%s/messages", c.baseURL, url.PathEscape(roomID))
+	form := url.Values{"body": {body}}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("リクエストの作成に失敗しました: %w", err)
+	}
+	req.Header.Set("X-ChatWorkToken", c.token)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("APIリクエストに失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("レスポンスの読み取りに失敗しました: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	var result struct {
+		MessageID string `json:"message_id"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("JSONのパースに失敗しました: %w", err)
+	}
+	return result.MessageID, nil
+}
@@ -0,0 +1,89 @@
+package chatwork
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Message FakeServerが受け取ったメッセージ
+type Message struct {
+	MessageID string
+	RoomID    string
+	Body      string
+}
+
+// FakeServer Chatwork APIのメッセージ投稿だけを模したhttp.Handler
+// テストではhttptest.NewServer(fake)で起動し、そのURLをNewAPIClientに渡して使う
+type FakeServer struct {
+	mu         sync.Mutex
+	messages   []Message
+	failures   int
+	failStatus int
+}
+
+// NewFakeServer 空のFakeServerを作成
+func NewFakeServer() *FakeServer {
+	return &FakeServer{}
+}
+
+// FailNext 次のn回の投稿をstatusで失敗させる
+func (f *FakeServer) FailNext(n, status int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = n
+	f.failStatus = status
+}
+
+// Messages これまでに受け取ったメッセージ
+func (f *FakeServer) Messages() []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Message(nil), f.messages...)
+}
+
+// ServeHTTP POST /rooms/{room_id}/messages を処理する
+func (f *FakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if r.Method != http.MethodPost || len(parts) < 3 || parts[len(parts)-3] != "rooms" || parts[len(parts)-1] != "messages" {
+		writeFakeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	if r.Header.Get("X-ChatWorkToken") == "" {
+		writeFakeError(w, http.StatusUnauthorized, "Invalid API token")
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.failures > 0 {
+		f.failures--
+		writeFakeError(w, f.failStatus, "Fake failure")
+		return
+	}
+
+	body := r.FormValue("body")
+	if body == "" {
+		writeFakeError(w, http.StatusBadRequest, "Parameter [body] is required")
+		return
+	}
+
+	message := Message{
+		MessageID: strconv.Itoa(len(f.messages) + 1),
+		RoomID:    parts[len(parts)-2],
+		Body:      body,
+	}
+	f.messages = append(f.messages, message)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message_id": message.MessageID})
+}
+
+func writeFakeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string][]string{"errors": {message}})
+}
@@ -0,0 +1,144 @@
+package chatwork
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Chatwork記法のタグ（対応していないタグは文字列のまま表示する）
+var (
+	markupTagRegex = regexp.MustCompile(`\[(/?)(info|title|qt|code|hr|toall|To:\d+|rp [^\]\n]*|picon:\d+|piconname:\d+|qtmeta [^\]\n]*)\]`)
+	markupURLRegex = regexp.MustCompile(`https?://[^\s\[\]<>"'` + "`" + `]+`)
+	markupAIDRegex = regexp.MustCompile(`aid=(\d+)`)
+)
+
+// 開きタグと閉じタグの組になるタグと、変換後のHTML
+var markupBlocks = map[string]struct{ open, close string }{
+	"info":  {`<div class="cw-info">`, `</div>`},
+	"title": {`<div class="cw-title">`, `</div>`},
+	"qt":    {`<blockquote class="cw-quote">`, `</blockquote>`},
+}
+
+// RenderHTML Chatwork記法のメッセージを投稿後の見た目に近いHTMLに変換する
+// 文字列はすべてエスケープし、出力するタグは変換で生成したものだけにする
+// 閉じられていないタグは末尾で閉じ、対応する開きタグのない閉じタグは文字列として表示する
+func RenderHTML(body string) string {
+	var b strings.Builder
+	var stack []string
+
+	rest := strings.ReplaceAll(body, "\r\n", "\n")
+	for rest != "" {
+		loc := markupTagRegex.FindStringSubmatchIndex(rest)
+		if loc == nil {
+			writeMarkupText(&b, rest)
+			break
+		}
+		writeMarkupText(&b, rest[:loc[0]])
+		closing := loc[3] > loc[2]
+		name := rest[loc[4]:loc[5]]
+		tag := rest[loc[0]:loc[1]]
+		rest = rest[loc[1]:]
+
+		switch {
+		case name == "code" && !closing:
+			// [code]の中は記法として解釈しない
+			end := strings.Index(rest, "[/code]")
+			if end < 0 {
+				end = len(rest)
+			}
+			b.WriteString(`<pre class="cw-code">`)
+			b.WriteString(html.EscapeString(strings.Trim(rest[:end], "\n")))
+			b.WriteString(`</pre>`)
+			rest = strings.TrimPrefix(strings.TrimPrefix(rest[end:], "[/code]"), "\n")
+		case name == "hr" && !closing:
+			b.WriteString(`<hr class="cw-hr">`)
+			rest = strings.TrimPrefix(rest, "\n")
+		case name == "toall" && !closing:
+			b.WriteString(`<span class="cw-to">TO ALL</span>`)
+		case strings.HasPrefix(name, "To:") && !closing:
+			b.WriteString(`<span class="cw-to">TO</span>`)
+		case strings.HasPrefix(name, "rp ") && !closing:
+			b.WriteString(`<span class="cw-reply">RE</span>`)
+		case strings.HasPrefix(name, "picon"):
+			if closing {
+				writeMarkupText(&b, tag)
+				continue
+			}
+			b.WriteString(`<span class="cw-picon">`)
+			b.WriteString(html.EscapeString(name[strings.Index(name, ":")+1:]))
+			b.WriteString(`</span>`)
+		case strings.HasPrefix(name, "qtmeta "):
+			if closing {
+				writeMarkupText(&b, tag)
+				continue
+			}
+			if m := markupAIDRegex.FindStringSubmatch(name); m != nil {
+				b.WriteString(`<div class="cw-quote-meta">`)
+				b.WriteString(html.EscapeString(m[1]))
+				b.WriteString(`</div>`)
+			}
+		default:
+			block, ok := markupBlocks[name]
+			if !ok {
+				writeMarkupText(&b, tag)
+				continue
+			}
+			if !closing {
+				stack = append(stack, name)
+				b.WriteString(block.open)
+				rest = strings.TrimPrefix(rest, "\n")
+				continue
+			}
+			depth := lastIndex(stack, name)
+			if depth < 0 {
+				writeMarkupText(&b, tag)
+				continue
+			}
+			// 内側で閉じられていないタグもあわせて閉じる
+			for len(stack) > depth {
+				b.WriteString(markupBlocks[stack[len(stack)-1]].close)
+				stack = stack[:len(stack)-1]
+			}
+			rest = strings.TrimPrefix(rest, "\n")
+		}
+	}
+
+	for len(stack) > 0 {
+		b.WriteString(markupBlocks[stack[len(stack)-1]].close)
+		stack = stack[:len(stack)-1]
+	}
+	return b.String()
+}
+
+// writeMarkupText 文字列をエスケープし、URLをリンクに、改行を<br>にして書き出す
+func writeMarkupText(b *strings.Builder, text string) {
+	for text != "" {
+		loc := markupURLRegex.FindStringIndex(text)
+		if loc == nil {
+			writeMarkupLines(b, text)
+			return
+		}
+		writeMarkupLines(b, text[:loc[0]])
+		link := html.EscapeString(text[loc[0]:loc[1]])
+		b.WriteString(`<a href="`)
+		b.WriteString(link)
+		b.WriteString(`" target="_blank" rel="noopener noreferrer">`)
+		b.WriteString(link)
+		b.WriteString(`</a>`)
+		text = text[loc[1]:]
+	}
+}
+
+func writeMarkupLines(b *strings.Builder, text string) {
+	b.WriteString(strings.ReplaceAll(html.EscapeString(text), "\n", "<br>"))
+}
+
+func lastIndex(values []string, s string) int {
+	for i := len(values) - 1; i >= 0; i-- {
+		if values[i] == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -1,20 +1,64 @@
 package controllers
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"login-app/models"
+	"login-app/services"
 
 	"github.com/labstack/echo-contrib/session"
 	"github.com/labstack/echo/v4"
 )
 
-type ArticleController struct{}
+// 履歴の1ページあたりの件数
+const (
+	defaultHistoryPerPage = 20
+	maxHistoryPerPage     = 100
+)
+
+// 入力値の最大文字数
+const (
+	maxNoteLength    = 2000
+	maxTitleLength   = 200
+	maxSummaryLength = 2000
+)
+
+// 一度に補完する記事の最大件数
+const maxEnrichArticles = 20
+
+type ArticleController struct {
+	metadata      *services.MetadataFetcher
+	canonicalizer *services.URLCanonicalizer
+	summarizer    *services.ArticleSummarizer
+	links         *services.LinkSigner
+}
+
+// articleResponse 記事一覧で返す記事（記事の状態を含む）
+type articleResponse struct {
+	models.Article
+	IsRead    bool   `json:"is_read"`
+	IsStarred bool   `json:"is_starred"`
+	Note      string `json:"note"`
+	Vote      string `json:"vote,omitempty"` // "up" または "down"
+
+	// クリックを記録して記事のURLに転送するリンク
+	TrackingURL string `json:"tracking_url,omitempty"`
+	LinkBroken  bool   `json:"link_broken"`
 
-func NewArticleController() *ArticleController {
-	return &ArticleController{}
+	// 内容が類似した記事のまとまり（代表の記事には他の記事のIDを、それ以外には代表の記事のIDを入れる）
+	SimilarArticleIDs []int64 `json:"similar_article_ids,omitempty"`
+	NearDuplicateOf   int64   `json:"near_duplicate_of,omitempty"`
+}
+
+func NewArticleController(metadata *services.MetadataFetcher, canonicalizer *services.URLCanonicalizer, summarizer *services.ArticleSummarizer, links *services.LinkSigner) *ArticleController {
+	return &ArticleController{metadata: metadata, canonicalizer: canonicalizer, summarizer: summarizer, links: links}
 }
 
 // ShowArticles 過去の記事一覧ページを表示
@@ -33,7 +77,7 @@ func (ac *ArticleController) ShowArticles(c echo.Context) error {
 	return c.File("views/articles.html")
 }
 
-// GetArticles ルームIDに紐づく記事一覧を取得
+// GetArticles ルームIDに紐づく記事一覧を取得（既定では配信順に並べる）
 func (ac *ArticleController) GetArticles(c echo.Context) error {
 	sess, err := session.Get("login-session", c)
 	if err != nil {
@@ -57,50 +101,789 @@ func (ac *ArticleController) GetArticles(c echo.Context) error {
 		})
 	}
 
-	supabaseURL := os.Getenv("SUPABASE_URL")
-	supabaseKey := os.Getenv("SUPABASE_KEY")
+	// 既読・スターでの絞り込み条件（未指定の場合は絞り込まない）
+	readFilter, err := parseBoolFilter(c.QueryParam("read"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "readはtrueまたはfalseを指定してください",
+		})
+	}
+	starredFilter, err := parseBoolFilter(c.QueryParam("starred"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "starredはtrueまたはfalseを指定してください",
+		})
+	}
+	noteFilter, err := parseBoolFilter(c.QueryParam("has_note"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "has_noteはtrueまたはfalseを指定してください",
+		})
+	}
+
+	// trueの場合は類似記事のまとまりを代表の記事1件にまとめる
+	collapse, err := parseBoolFilter(c.QueryParam("collapse"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "collapseはtrueまたはfalseを指定してください",
+		})
+	}
+
+	// リンクの確認結果で絞り込む（broken: リンク切れ、ok: 確認済みで問題なし、unchecked: 未確認）
+	linkFilter := c.QueryParam("link")
+	if linkFilter != "" && linkFilter != "broken" && linkFilter != "ok" && linkFilter != "unchecked" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "linkにはbroken、ok、uncheckedのいずれかを指定してください",
+		})
+	}
+
+	// 並び順（queue: 配信順（既定）、score: 関連度順、created: 登録順）
+	sortBy := c.QueryParam("sort")
+	if sortBy != "" && sortBy != "queue" && sortBy != "score" && sortBy != "created" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "sortにはqueue、score、createdのいずれかを指定してください",
+		})
+	}
+
+	// 記事一覧を取得（採点は分野や記事の内容を変えたときに行い、ここでは保存済みのスコアを使う）
+	articles, err := models.FetchArticles(roomID)
+	if err != nil {
+		fmt.Printf("記事一覧の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "記事一覧の取得に失敗しました",
+		})
+	}
+	switch sortBy {
+	case "score":
+		services.SortArticlesByScore(articles)
+	case "created":
+		services.SortArticlesByCreated(articles)
+	default:
+		services.SortArticlesByQueue(articles)
+	}
+
+	states, err := models.FetchArticleStates(roomID)
+	if err != nil {
+		fmt.Printf("記事状態の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "記事状態の取得に失敗しました",
+		})
+	}
+
+	votes, err := models.FetchArticleVotes(roomID)
+	if err != nil {
+		fmt.Printf("記事の評価の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "記事の評価の取得に失敗しました",
+		})
+	}
+
+	// 類似記事のまとまりは絞り込み前の全記事で判定する
+	similar := make(map[int64][]int64)
+	duplicateOf := make(map[int64]int64)
+	for _, cluster := range services.FindNearDuplicates(articles) {
+		for _, id := range cluster.Members {
+			if id == cluster.Representative {
+				continue
+			}
+			similar[cluster.Representative] = append(similar[cluster.Representative], id)
+			duplicateOf[id] = cluster.Representative
+		}
+	}
+
+	responseArticles := make([]articleResponse, 0, len(articles))
+	for _, article := range articles {
+		if collapse != nil && *collapse && duplicateOf[article.ArticleID] != 0 {
+			continue
+		}
+		state := states[article.ArticleID]
+		if readFilter != nil && state.IsRead != *readFilter {
+			continue
+		}
+		if starredFilter != nil && state.IsStarred != *starredFilter {
+			continue
+		}
+		if noteFilter != nil && (state.Note != "") != *noteFilter {
+			continue
+		}
+		broken := services.IsBrokenLink(article)
+		switch linkFilter {
+		case "broken":
+			if !broken {
+				continue
+			}
+		case "ok":
+			if broken || article.LinkCheckedAt == nil {
+				continue
+			}
+		case "unchecked":
+			if article.LinkCheckedAt != nil {
+				continue
+			}
+		}
+		var trackingURL string
+		if articleURL := models.ParseArticleContent(article.Content).URL; articleURL != "" {
+			trackingURL = ac.links.Path(services.ClickTarget{
+				RoomID:    roomID,
+				ArticleID: article.ArticleID,
+				URL:       articleURL,
+				Source:    models.ClickSourceWeb,
+			})
+		}
+		responseArticles = append(responseArticles, articleResponse{
+			Article:           article,
+			IsRead:            state.IsRead,
+			IsStarred:         state.IsStarred,
+			Note:              state.Note,
+			Vote:              votes[article.ArticleID],
+			TrackingURL:       trackingURL,
+			LinkBroken:        broken,
+			SimilarArticleIDs: similar[article.ArticleID],
+			NearDuplicateOf:   duplicateOf[article.ArticleID],
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"articles": responseArticles,
+	})
+}
+
+// UpdateArticleState 記事の既読・スター・メモを更新
+// 指定された項目のみを更新する
+func (ac *ArticleController) UpdateArticleState(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	articleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効な記事IDです",
+		})
+	}
+
+	var req struct {
+		IsRead    *bool   `json:"is_read"`
+		IsStarred *bool   `json:"is_starred"`
+		Note      *string `json:"note"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効なリクエストです",
+		})
+	}
+
+	changes := make(map[string]interface{})
+	if req.IsRead != nil {
+		changes["is_read"] = *req.IsRead
+	}
+	if req.IsStarred != nil {
+		changes["is_starred"] = *req.IsStarred
+	}
+	if req.Note != nil {
+		if utf8.RuneCountInString(*req.Note) > maxNoteLength {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"message": fmt.Sprintf("メモは%d文字以内で入力してください", maxNoteLength),
+			})
+		}
+		changes["note"] = *req.Note
+	}
+	if len(changes) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "更新する項目が指定されていません",
+		})
+	}
+
+	// 他のルームの記事は更新できないようにする
+	article, err := models.FetchArticle(roomID, articleID)
+	if err != nil {
+		fmt.Printf("記事の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "記事の取得に失敗しました",
+		})
+	}
+	if article == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"message": "記事が見つかりません",
+		})
+	}
+
+	if err := models.UpdateArticleState(roomID, articleID, changes); err != nil {
+		fmt.Printf("記事状態の更新エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "記事状態の更新に失敗しました",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "記事の状態を更新しました",
+	})
+}
+
+// RecordFeedback 記事への反応（👍・👎・取り消し・クリック）を記録
+// 反応した時点で記事に一致している分野とともに記録し、分野ごとの優先度の調整に使う
+func (ac *ArticleController) RecordFeedback(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	articleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効な記事IDです",
+		})
+	}
+
+	var req struct {
+		Signal string `json:"signal"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効なリクエストです",
+		})
+	}
+	if !models.ValidFeedbackSignal(req.Signal) {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "signalにはup、down、clear、clickのいずれかを指定してください",
+		})
+	}
+
+	article, err := models.FetchArticle(roomID, articleID)
+	if err != nil {
+		fmt.Printf("記事の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "記事の取得に失敗しました",
+		})
+	}
+	if article == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"message": "記事が見つかりません",
+		})
+	}
+
+	if err := models.RecordArticleFeedback(*article, req.Signal); err != nil {
+		fmt.Printf("記事への反応の記録エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "記事への反応の記録に失敗しました",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "記事への反応を記録しました",
+	})
+}
+
+// CreateArticle URLを指定して記事を手動で登録
+// 同じルームに正規化後のURLが一致する記事がある場合は登録しない
+func (ac *ArticleController) CreateArticle(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	var req struct {
+		URL     string   `json:"url"`
+		Title   string   `json:"title"`
+		Summary string   `json:"summary"`
+		Fields  []string `json:"fields"`
+		Tags    []string `json:"tags"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効なリクエストです",
+		})
+	}
+
+	// 計測用パラメータや短縮URLを取り除いた形で保存する
+	articleURL, err := ac.canonicalizer.Canonicalize(c.Request().Context(), req.URL)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	normalizedURL, err := models.NormalizeArticleURL(articleURL)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	if utf8.RuneCountInString(req.Title) > maxTitleLength {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": fmt.Sprintf("タイトルは%d文字以内で入力してください", maxTitleLength),
+		})
+	}
+	if utf8.RuneCountInString(req.Summary) > maxSummaryLength {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": fmt.Sprintf("要約は%d文字以内で入力してください", maxSummaryLength),
+		})
+	}
+
+	// 分野はルームに登録済みのものだけを受け付ける
+	var fieldNames []string
+	if len(req.Fields) > 0 {
+		registered, err := models.FetchFields(roomID)
+		if err != nil {
+			fmt.Printf("分野一覧の取得エラー: %v\n", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"message": "分野一覧の取得に失敗しました",
+			})
+		}
+		known := make(map[string]bool, len(registered))
+		for _, field := range registered {
+			known[field.FieldName] = true
+		}
+		for _, name := range req.Fields {
+			name = models.NormalizeFieldName(name)
+			if name == "" {
+				continue
+			}
+			if !known[name] {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"message": fmt.Sprintf("分野「%s」は登録されていません", name),
+				})
+			}
+			fieldNames = append(fieldNames, name)
+		}
+	}
+
+	existing, err := models.FindArticleByURL(roomID, normalizedURL)
+	if err != nil {
+		fmt.Printf("重複チェックエラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "記事一覧の取得に失敗しました",
+		})
+	}
+	if existing != nil {
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"message":    "同じURLの記事がすでに登録されています",
+			"article_id": existing.ArticleID,
+		})
+	}
+
+	content := models.RenderArticleContent(models.ArticleContent{
+		Field:   strings.Join(fieldNames, "、"),
+		Title:   req.Title,
+		URL:     articleURL,
+		Summary: req.Summary,
+		Tags:    req.Tags,
+	})
 
-	// 記事一覧を取得するクエリ
-	fullURL := supabaseURL + "/rest/v1/reserve_article?select=*&room_id=eq." + roomID
+	article, err := models.CreateArticle(roomID, content)
+	if err != nil {
+		fmt.Printf("記事の登録エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "記事の登録に失敗しました",
+		})
+	}
+	if err := services.RescoreArticle(article); err != nil {
+		fmt.Printf("記事の採点エラー - Article ID: %d, Error: %v\n", article.ArticleID, err)
+	}
+
+	// タイトル・要約が未入力の場合はページのメタデータで補完する（失敗しても登録は成功とする）
+	if _, err := ac.metadata.EnrichArticle(c.Request().Context(), article); err != nil {
+		fmt.Printf("メタデータの取得エラー - URL: %s, Error: %v\n", req.URL, err)
+	}
+	// メタデータにも説明がない場合はページ本文から要約する
+	if models.ParseArticleContent(article.Content).Summary == "" {
+		if _, err := ac.summarizer.Summarize(c.Request().Context(), article); err != nil {
+			fmt.Printf("要約の作成エラー - URL: %s, Error: %v\n", req.URL, err)
+		}
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "記事を登録しました",
+		"article": article,
+	})
+}
 
-	req, err := http.NewRequest("GET", fullURL, nil)
+// GetURLMetadata URLのページからタイトル・説明などのメタデータを取得
+func (ac *ArticleController) GetURLMetadata(c echo.Context) error {
+	if _, err := getSessionRoomID(c); err != nil {
+		return err
+	}
+
+	meta, err := ac.metadata.Fetch(c.Request().Context(), c.QueryParam("url"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": fmt.Sprintf("メタデータを取得できませんでした: %v", err),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"metadata": meta,
+	})
+}
+
+// EnrichArticle 記事1件のタイトル・要約をページのメタデータで補完
+func (ac *ArticleController) EnrichArticle(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
 	if err != nil {
+		return err
+	}
+
+	articleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効な記事IDです",
+		})
+	}
+
+	article, err := models.FetchArticle(roomID, articleID)
+	if err != nil {
+		fmt.Printf("記事の取得エラー: %v\n", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"message": "リクエストの作成に失敗しました",
+			"message": "記事の取得に失敗しました",
+		})
+	}
+	if article == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"message": "記事が見つかりません",
 		})
 	}
 
-	req.Header.Set("apikey", supabaseKey)
-	req.Header.Set("Authorization", "Bearer "+supabaseKey)
-	req.Header.Set("Content-Type", "application/json")
+	enriched, err := ac.metadata.EnrichArticle(c.Request().Context(), article)
+	if err != nil {
+		return c.JSON(http.StatusBadGateway, map[string]string{
+			"message": fmt.Sprintf("メタデータを取得できませんでした: %v", err),
+		})
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	message := "補完する項目はありませんでした"
+	if enriched {
+		message = "記事のタイトル・要約を補完しました"
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": message,
+		"article": article,
+	})
+}
+
+// SummarizeArticle 記事URLのページ本文から要約を作り直す
+func (ac *ArticleController) SummarizeArticle(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
 	if err != nil {
+		return err
+	}
+
+	articleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効な記事IDです",
+		})
+	}
+
+	article, err := models.FetchArticle(roomID, articleID)
+	if err != nil {
+		fmt.Printf("記事の取得エラー: %v\n", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"message": "APIリクエストに失敗しました",
+			"message": "記事の取得に失敗しました",
+		})
+	}
+	if article == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"message": "記事が見つかりません",
+		})
+	}
+
+	summary, err := ac.summarizer.Summarize(c.Request().Context(), article)
+	if err != nil {
+		fmt.Printf("要約の作成エラー - 記事ID: %d, Error: %v\n", articleID, err)
+		return c.JSON(http.StatusBadGateway, map[string]string{
+			"message": fmt.Sprintf("要約を作成できませんでした: %v", err),
 		})
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "要約を作成しました",
+		"summary": summary,
+		"article": article,
+	})
+}
+
+// EnrichArticles タイトルまたは要約が空の記事をまとめて補完（1回あたり最大maxEnrichArticles件）
+func (ac *ArticleController) EnrichArticles(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
 	if err != nil {
+		return err
+	}
+
+	articles, err := models.FetchArticles(roomID)
+	if err != nil {
+		fmt.Printf("記事一覧の取得エラー: %v\n", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"message": "レスポンスの読み取りに失敗しました",
+			"message": "記事一覧の取得に失敗しました",
 		})
 	}
 
-	var articles []map[string]interface{}
-	if err := json.Unmarshal(body, &articles); err != nil {
+	enriched, failed, attempted := 0, 0, 0
+	for i := range articles {
+		content := models.ParseArticleContent(articles[i].Content)
+		if content.URL == "" || (content.Title != "" && content.Summary != "") {
+			continue
+		}
+		if attempted >= maxEnrichArticles {
+			break
+		}
+		attempted++
+
+		ok, err := ac.metadata.EnrichArticle(c.Request().Context(), &articles[i])
+		if err != nil {
+			fmt.Printf("メタデータの取得エラー - 記事ID: %d, Error: %v\n", articles[i].ArticleID, err)
+			failed++
+			continue
+		}
+		if ok {
+			enriched++
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":  fmt.Sprintf("%d件の記事を補完しました（取得失敗: %d件）", enriched, failed),
+		"enriched": enriched,
+		"failed":   failed,
+	})
+}
+
+// GetDuplicateArticles 正規化後のURLが一致する記事のまとまりを取得
+func (ac *ArticleController) GetDuplicateArticles(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	articles, err := models.FetchArticles(roomID)
+	if err != nil {
+		fmt.Printf("記事一覧の取得エラー: %v\n", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"message": "JSONのパースに失敗しました",
+			"message": "記事一覧の取得に失敗しました",
 		})
 	}
 
+	groups := services.FindDuplicateArticles(articles)
+	if groups == nil {
+		groups = []services.DuplicateGroup{}
+	}
+
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"articles": articles,
+		"groups": groups,
 	})
 }
 
+// MergeDuplicateArticles 重複した記事を1件に統合
+// keep_idとarticle_idsを指定した場合はその記事を、allがtrueの場合はすべての重複を統合する
+func (ac *ArticleController) MergeDuplicateArticles(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	var req struct {
+		KeepID     int64   `json:"keep_id"`
+		ArticleIDs []int64 `json:"article_ids"`
+		All        bool    `json:"all"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効なリクエストです",
+		})
+	}
+
+	if !req.All {
+		if req.KeepID == 0 || len(req.ArticleIDs) == 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"message": "残す記事（keep_id）と統合する記事（article_ids）を指定してください",
+			})
+		}
+		article, err := services.MergeDuplicateArticles(roomID, req.KeepID, req.ArticleIDs)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"message": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"message": "記事を統合しました",
+			"article": article,
+		})
+	}
+
+	articles, err := models.FetchArticles(roomID)
+	if err != nil {
+		fmt.Printf("記事一覧の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "記事一覧の取得に失敗しました",
+		})
+	}
+
+	merged := 0
+	var errors []string
+	for _, group := range services.FindDuplicateArticles(articles) {
+		ids := make([]int64, 0, len(group.Articles))
+		for _, article := range group.Articles {
+			ids = append(ids, article.ArticleID)
+		}
+		if _, err := services.MergeDuplicateArticles(roomID, group.KeepID, ids); err != nil {
+			fmt.Printf("記事の統合エラー - URL: %s, Error: %v\n", group.URL, err)
+			errors = append(errors, fmt.Sprintf("%s: %v", group.URL, err))
+			continue
+		}
+		merged += len(ids) - 1
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": fmt.Sprintf("%d件の重複した記事を統合しました", merged),
+		"merged":  merged,
+		"errors":  nonNil(errors),
+	})
+}
+
+// ShowArchive 配信・削除済み記事のアーカイブページを表示
+func (ac *ArticleController) ShowArchive(c echo.Context) error {
+	if _, err := getSessionRoomID(c); err != nil {
+		return c.Redirect(http.StatusSeeOther, "/")
+	}
+
+	return c.File("views/archive.html")
+}
+
+// GetArticleHistory 配信・削除済み記事の履歴を検索
+// q でタイトル・URL・要約を部分一致検索し、event（delivered/deleted）で絞り込み、page・per_pageでページ分割する
+func (ac *ArticleController) GetArticleHistory(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	event := c.QueryParam("event")
+	if event != "" && event != models.HistoryEventDelivered && event != models.HistoryEventDeleted {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "eventはdeliveredまたはdeletedを指定してください",
+		})
+	}
+
+	page, perPage, err := parsePagination(c, defaultHistoryPerPage, maxHistoryPerPage)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	histories, total, err := models.SearchArticleHistory(roomID, models.HistoryQuery{
+		Keyword: c.QueryParam("q"),
+		Event:   event,
+		Limit:   perPage,
+		Offset:  (page - 1) * perPage,
+	})
+	if err != nil {
+		fmt.Printf("履歴の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "履歴の取得に失敗しました",
+		})
+	}
+	if histories == nil {
+		histories = []models.ArticleHistory{}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"histories": histories,
+		"total":     total,
+		"page":      page,
+		"per_page":  perPage,
+	})
+}
+
+// parsePagination page（1始まり）とper_pageを解析する
+func parsePagination(c echo.Context, defaultPerPage, maxPerPage int) (int, int, error) {
+	page, perPage := 1, defaultPerPage
+
+	if v := c.QueryParam("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return 0, 0, fmt.Errorf("pageは1以上の数値で指定してください")
+		}
+		page = n
+	}
+	if v := c.QueryParam("per_page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPerPage {
+			return 0, 0, fmt.Errorf("per_pageは1から%dの間で指定してください", maxPerPage)
+		}
+		perPage = n
+	}
+
+	return page, perPage, nil
+}
+
+// DeleteBrokenArticles リンク切れの記事をまとめて履歴に記録してから削除
+func (ac *ArticleController) DeleteBrokenArticles(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	articles, err := models.FetchArticles(roomID)
+	if err != nil {
+		fmt.Printf("記事一覧の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "記事一覧の取得に失敗しました",
+		})
+	}
+
+	now := time.Now().UTC()
+	deleted := 0
+	for _, article := range articles {
+		if !services.IsBrokenLink(article) {
+			continue
+		}
+		if err := models.ArchiveArticle(article, models.HistoryEventDeleted, now); err != nil {
+			fmt.Printf("履歴の保存エラー - Article ID: %d, Error: %v\n", article.ArticleID, err)
+			continue
+		}
+		if err := models.DeleteArticle(roomID, article.ArticleID); err != nil {
+			fmt.Printf("記事の削除エラー - Article ID: %d, Error: %v\n", article.ArticleID, err)
+			continue
+		}
+		deleted++
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": fmt.Sprintf("リンク切れの記事を%d件削除しました", deleted),
+		"deleted": deleted,
+	})
+}
+
+// archiveBeforeDelete 削除する記事を履歴に記録する
+func archiveBeforeDelete(roomID, articleID string) error {
+	id, err := strconv.ParseInt(articleID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("無効な記事IDです: %s", articleID)
+	}
+
+	article, err := models.FetchArticle(roomID, id)
+	if err != nil {
+		return err
+	}
+	if article == nil {
+		// 既に削除されている場合は記録するものがない
+		return nil
+	}
+
+	return models.ArchiveArticle(*article, models.HistoryEventDeleted, time.Now().UTC())
+}
+
+// parseBoolFilter "true"/"false"のクエリを解析する（空の場合はnil）
+func parseBoolFilter(value string) (*bool, error) {
+	if value == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
 // DeleteArticles 選択された記事を削除
 func (ac *ArticleController) DeleteArticles(c echo.Context) error {
 	sess, err := session.Get("login-session", c)
@@ -148,6 +931,12 @@ func (ac *ArticleController) DeleteArticles(c echo.Context) error {
 
 	// 各記事を削除
 	for _, articleID := range req.ArticleIDs {
+		// 削除前に履歴へ残す（残せなかった記事は削除しない）
+		if err := archiveBeforeDelete(roomID, articleID); err != nil {
+			fmt.Printf("履歴の保存エラー - Article ID: %s, Error: %v\n", articleID, err)
+			continue
+		}
+
 		deleteURL := fmt.Sprintf("%s/rest/v1/reserve_article?article_id=eq.%s&room_id=eq.%s", supabaseURL, articleID, roomID)
 		fmt.Printf("削除URL: %s\n", deleteURL)
 
@@ -0,0 +1,339 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"login-app/models"
+	"login-app/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 分野を指定せずに登録した記事の本文に入る分野名
+const manualArticleField = "手動追加"
+
+// UpdateArticle 記事のタイトル・URL・要約を編集し、分野を手動で付け外しする
+// 指定された項目だけを変更して本文を作り直し、採点し直す。反映した変更内容は編集の記録に残す
+// 読み込んだ後にほかの操作で本文が変わっていた場合は更新せずに409を返す
+func (ac *ArticleController) UpdateArticle(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	articleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効な記事IDです",
+		})
+	}
+
+	var req struct {
+		Title        *string  `json:"title"`
+		URL          *string  `json:"url"`
+		Summary      *string  `json:"summary"`
+		AttachFields []string `json:"attach_fields"`
+		DetachFields []string `json:"detach_fields"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効なリクエストです",
+		})
+	}
+	if req.Title == nil && req.URL == nil && req.Summary == nil && len(req.AttachFields) == 0 && len(req.DetachFields) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "更新する項目が指定されていません",
+		})
+	}
+
+	article, err := models.FetchArticle(roomID, articleID)
+	if err != nil {
+		fmt.Printf("記事の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "記事の取得に失敗しました",
+		})
+	}
+	if article == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"message": "記事が見つかりません",
+		})
+	}
+
+	before := models.ParseArticleContent(article.Content)
+	content := before
+
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if utf8.RuneCountInString(title) > maxTitleLength {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"message": fmt.Sprintf("タイトルは%d文字以内で入力してください", maxTitleLength),
+			})
+		}
+		content.Title = title
+	}
+	if req.Summary != nil {
+		summary := strings.TrimSpace(*req.Summary)
+		if utf8.RuneCountInString(summary) > maxSummaryLength {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"message": fmt.Sprintf("要約は%d文字以内で入力してください", maxSummaryLength),
+			})
+		}
+		content.Summary = summary
+	}
+
+	urlChanged := false
+	if req.URL != nil {
+		articleURL, err := ac.canonicalizer.Canonicalize(c.Request().Context(), *req.URL)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"message": err.Error(),
+			})
+		}
+		if articleURL != before.URL {
+			// 同じルームの別の記事と同じURLにはできない
+			normalizedURL, err := models.NormalizeArticleURL(articleURL)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"message": err.Error(),
+				})
+			}
+			existing, err := models.FindArticleByURL(roomID, normalizedURL)
+			if err != nil {
+				fmt.Printf("重複チェックエラー: %v\n", err)
+				return c.JSON(http.StatusInternalServerError, map[string]string{
+					"message": "記事一覧の取得に失敗しました",
+				})
+			}
+			if existing != nil && existing.ArticleID != articleID {
+				return c.JSON(http.StatusConflict, map[string]interface{}{
+					"message":    "同じURLの記事がすでに登録されています",
+					"article_id": existing.ArticleID,
+				})
+			}
+			content.URL = articleURL
+			urlChanged = true
+		}
+	}
+
+	// 分野の付け外し（ルームに登録済みの分野だけを受け付ける）
+	fields, err := models.FetchFields(roomID)
+	if err != nil {
+		fmt.Printf("分野一覧の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "分野一覧の取得に失敗しました",
+		})
+	}
+	known := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		known[field.FieldName] = true
+	}
+	attach, err := registeredFieldNames(req.AttachFields, known)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	detach, err := registeredFieldNames(req.DetachFields, known)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	for _, name := range attach {
+		if containsString(detach, name) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"message": fmt.Sprintf("分野「%s」の付け外しが両方指定されています", name),
+			})
+		}
+	}
+
+	attached := splitArticleFields(before.Field)
+	detached := append([]string{}, article.DetachedFields...)
+	for _, name := range attach {
+		if !containsString(attached, name) {
+			attached = append(attached, name)
+		}
+		detached = removeString(detached, name)
+	}
+	for _, name := range detach {
+		attached = removeString(attached, name)
+		if !containsString(detached, name) {
+			detached = append(detached, name)
+		}
+	}
+	content.Field = strings.Join(attached, "、")
+
+	// 変更内容（変わらなかった項目は含めない）
+	changes := make(map[string]models.ArticleChange)
+	if content.Title != before.Title {
+		changes["title"] = models.ArticleChange{Old: before.Title, New: content.Title}
+	}
+	if urlChanged {
+		changes["url"] = models.ArticleChange{Old: before.URL, New: content.URL}
+	}
+	if content.Summary != before.Summary {
+		changes["summary"] = models.ArticleChange{Old: before.Summary, New: content.Summary}
+	}
+	if !sameStrings(attached, splitArticleFields(before.Field)) {
+		changes["fields"] = models.ArticleChange{Old: splitArticleFields(before.Field), New: attached}
+	}
+	if !sameStrings(detached, article.DetachedFields) {
+		changes["detached_fields"] = models.ArticleChange{Old: article.DetachedFields, New: detached}
+	}
+	if len(changes) == 0 {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"message": "変更はありません",
+			"article": article,
+		})
+	}
+
+	now := time.Now().UTC()
+	oldContent := article.Content
+	article.Content = models.RenderArticleContent(content)
+	article.DetachedFields = detached
+	result := services.ScoreStoredArticle(*article, fields)
+	article.Score = result.Score
+	article.MatchedFields = result.MatchedFields
+	article.ScoredAt = &now
+
+	update := map[string]interface{}{
+		"content":         article.Content,
+		"detached_fields": detached,
+		"score":           article.Score,
+		"matched_fields":  article.MatchedFields,
+		"scored_at":       now,
+	}
+	if urlChanged {
+		// URLを変えた場合はリンクの確認をやり直す
+		update["link_status"] = nil
+		update["link_error"] = ""
+		update["link_failures"] = 0
+		update["link_checked_at"] = nil
+		article.LinkStatus = nil
+		article.LinkError = ""
+		article.LinkFailures = 0
+		article.LinkCheckedAt = nil
+	}
+
+	// 読み込んだ後にほかの操作で本文が変わっていた場合は上書きしない
+	updated, err := models.UpdateArticleIfUnchanged(roomID, articleID, oldContent, update)
+	if err != nil {
+		fmt.Printf("記事の更新エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "記事の更新に失敗しました",
+		})
+	}
+	if !updated {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"message": "記事がほかの操作で変更されました。読み込み直してから編集してください",
+		})
+	}
+
+	// 反映した編集だけを記録する
+	if err := models.RecordArticleEdit(models.ArticleEdit{
+		RoomID:    roomID,
+		ArticleID: articleID,
+		Changes:   changes,
+		EditedAt:  now,
+	}); err != nil {
+		fmt.Printf("編集の記録エラー - 記事ID: %d, Error: %v\n", articleID, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "記事を更新しました",
+		"article": article,
+		"changes": changes,
+	})
+}
+
+// GetArticleEdits 記事の編集の記録を新しい順に取得
+func (ac *ArticleController) GetArticleEdits(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	articleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効な記事IDです",
+		})
+	}
+
+	edits, err := models.FetchArticleEdits(roomID, articleID)
+	if err != nil {
+		fmt.Printf("編集の記録の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "編集の記録の取得に失敗しました",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"edits": edits,
+	})
+}
+
+// registeredFieldNames 分野名を正規化し、登録済みの分野であることを確かめる
+func registeredFieldNames(names []string, known map[string]bool) ([]string, error) {
+	var result []string
+	for _, name := range names {
+		name = models.NormalizeFieldName(name)
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("分野「%s」は登録されていません", name)
+		}
+		if !containsString(result, name) {
+			result = append(result, name)
+		}
+	}
+	return result, nil
+}
+
+// splitArticleFields 本文の分野名（「、」区切り）を分ける（分野を指定せずに登録した記事は空）
+func splitArticleFields(field string) []string {
+	result := []string{}
+	for _, name := range strings.Split(field, "、") {
+		if name = strings.TrimSpace(name); name != "" && name != manualArticleField {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(values []string, s string) []string {
+	result := values[:0:0]
+	for _, v := range values {
+		if v != s {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
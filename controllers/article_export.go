@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"login-app/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ExportArticles ルームの記事をMarkdown・CSV・JSONで出力
+// status・field・q・since・untilで絞り込める。記事の多いルームでも全件を読み込まずに少しずつ書き出す
+func (ac *ArticleController) ExportArticles(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = services.ExportFormatJSON
+	}
+
+	q, err := services.ArticleExportQuery(roomID, c.QueryParam("status"), c.QueryParam("field"),
+		c.QueryParam("q"), c.QueryParam("since"), c.QueryParam("until"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	out, contentType, err := services.NewArticleExportWriter(c.Response(), format, roomID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, contentType)
+	c.Response().Header().Set(echo.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="articles-%s.%s"`, roomID, format))
+	c.Response().WriteHeader(http.StatusOK)
+
+	// 書き出しを始めた後はステータスを変えられないため、失敗はログにだけ残す
+	if err := services.ExportArticles(roomID, q, out, c.Response().Flush); err != nil {
+		fmt.Printf("記事のエクスポートエラー: %v\n", err)
+	}
+	return nil
+}
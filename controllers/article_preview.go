@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"login-app/chatwork"
+	"login-app/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PreviewArticle 記事を投稿したときのメッセージをHTMLで取得
+// 配信時と同じく記事URLを転送用リンクに書き換えた本文を変換する
+func (ac *ArticleController) PreviewArticle(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	articleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効な記事IDです",
+		})
+	}
+
+	article, err := models.FetchArticle(roomID, articleID)
+	if err != nil {
+		fmt.Printf("記事の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "記事の取得に失敗しました",
+		})
+	}
+	if article == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"message": "記事が見つかりません",
+		})
+	}
+
+	body := ac.links.TrackedContent(*article)
+	return c.JSON(http.StatusOK, map[string]string{
+		"body": body,
+		"html": chatwork.RenderHTML(body),
+	})
+}
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"login-app/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReorderArticles 未配信の記事の配信順を並べ替える
+// 指定した記事を先頭から順に並べ、指定しなかった記事は位置を外してスコア順で後ろに並べる
+func (ac *ArticleController) ReorderArticles(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	var req struct {
+		Order []int64 `json:"order"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効なリクエストです",
+		})
+	}
+	if len(req.Order) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "並び順が指定されていません",
+		})
+	}
+
+	articles, err := models.FetchArticles(roomID)
+	if err != nil {
+		fmt.Printf("記事一覧の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "記事一覧の取得に失敗しました",
+		})
+	}
+
+	queued := make(map[int64]bool, len(articles))
+	for _, article := range articles {
+		if article.DeliveredAt == nil {
+			queued[article.ArticleID] = true
+		}
+	}
+
+	var errors []string
+	seen := make(map[int64]bool)
+	for _, id := range req.Order {
+		if !queued[id] {
+			errors = append(errors, fmt.Sprintf("記事ID %d は未配信の記事にありません", id))
+			continue
+		}
+		if seen[id] {
+			errors = append(errors, fmt.Sprintf("記事ID %d が重複しています", id))
+		}
+		seen[id] = true
+	}
+	if len(errors) > 0 {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"message": "無効な記事が含まれています",
+			"errors":  errors,
+		})
+	}
+
+	if err := models.UpdateArticleQueue(roomID, req.Order); err != nil {
+		fmt.Printf("配信順の更新エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "配信順の更新に失敗しました",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": fmt.Sprintf("%d件の記事の配信順を更新しました", len(req.Order)),
+	})
+}
+
+// PinArticle 記事を配信順の先頭に固定する
+func (ac *ArticleController) PinArticle(c echo.Context) error {
+	return ac.setArticlePinned(c, true)
+}
+
+// UnpinArticle 記事の固定を解除する
+func (ac *ArticleController) UnpinArticle(c echo.Context) error {
+	return ac.setArticlePinned(c, false)
+}
+
+func (ac *ArticleController) setArticlePinned(c echo.Context, pinned bool) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	articleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効な記事IDです",
+		})
+	}
+
+	article, err := models.FetchArticle(roomID, articleID)
+	if err != nil {
+		fmt.Printf("記事の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "記事の取得に失敗しました",
+		})
+	}
+	if article == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"message": "記事が見つかりません",
+		})
+	}
+	if pinned && article.DeliveredAt != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "配信済みの記事は固定できません",
+		})
+	}
+
+	if err := models.UpdateArticle(articleID, map[string]interface{}{
+		"pinned": pinned,
+	}); err != nil {
+		fmt.Printf("記事の固定の更新エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "記事の固定の更新に失敗しました",
+		})
+	}
+
+	message := "記事の固定を解除しました"
+	if pinned {
+		message = "記事を固定しました"
+	}
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": message,
+	})
+}
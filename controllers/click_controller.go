@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"login-app/models"
+	"login-app/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ClickController 記事のリンクのクリックを記録して転送するコントローラー
+type ClickController struct {
+	links *services.LinkSigner
+}
+
+// NewClickController コントローラーのインスタンスを作成
+func NewClickController(links *services.LinkSigner) *ClickController {
+	return &ClickController{links: links}
+}
+
+// Redirect 転送用リンク（/r/{token}）のクリックを記録し、記事のURLに転送する
+// 配信したメッセージからも開かれるためログインは不要で、署名を検証できたトークンだけを転送する
+// リンクのプレビューを取得するボットのアクセスと、有効期限の切れたリンクのクリックは記録しない
+func (cc *ClickController) Redirect(c echo.Context) error {
+	target, err := cc.links.Verify(c.Param("token"))
+	expired := errors.Is(err, services.ErrExpiredLinkToken)
+	if err != nil && !expired {
+		return c.String(http.StatusNotFound, err.Error())
+	}
+
+	userAgent := services.AnonymizeUserAgent(c.Request().UserAgent())
+	if userAgent != "bot" && !expired {
+		cc.record(target, userAgent)
+	}
+
+	// 転送先にトークンを含むURLを送らない
+	c.Response().Header().Set("Referrer-Policy", "no-referrer")
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.Redirect(http.StatusFound, target.URL)
+}
+
+// record クリックを記録し、分野ごとの反応の集計にも加える（失敗しても転送は行う）
+func (cc *ClickController) record(target services.ClickTarget, userAgent string) {
+	source := target.Source
+	switch source {
+	case models.ClickSourceChatwork, models.ClickSourceWeb, models.ClickSourceFeed:
+	default:
+		source = models.ClickSourceWeb
+	}
+	if err := models.RecordArticleClick(models.ArticleClick{
+		RoomID:    target.RoomID,
+		ArticleID: target.ArticleID,
+		URL:       target.URL,
+		Source:    source,
+		UserAgent: userAgent,
+		ClickedAt: time.Now().UTC(),
+	}); err != nil {
+		fmt.Printf("クリックの記録エラー: %v\n", err)
+	}
+
+	// 一致した分野は予約中の記事か、配信・削除済みの場合は履歴から取る
+	article, err := models.FetchArticle(target.RoomID, target.ArticleID)
+	if err != nil {
+		fmt.Printf("記事の取得エラー: %v\n", err)
+		return
+	}
+	if article == nil {
+		history, err := models.FetchArticleHistory(target.RoomID, target.ArticleID)
+		if err != nil {
+			fmt.Printf("記事の履歴の取得エラー: %v\n", err)
+			return
+		}
+		if history == nil {
+			return
+		}
+		article = &models.Article{RoomID: history.RoomID, ArticleID: history.ArticleID, MatchedFields: history.MatchedFields}
+	}
+	if err := models.RecordArticleFeedback(*article, models.FeedbackClick); err != nil {
+		fmt.Printf("記事への反応の記録エラー: %v\n", err)
+	}
+}
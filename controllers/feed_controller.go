@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"login-app/models"
+	"login-app/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// FeedController RSS/Atomフィードの購読に関するコントローラー
+type FeedController struct {
+	poller *services.FeedPoller
+}
+
+// NewFeedController コントローラーのインスタンスを作成
+func NewFeedController(poller *services.FeedPoller) *FeedController {
+	return &FeedController{poller: poller}
+}
+
+// GetFeeds ルームが購読しているフィードの一覧を取得
+func (fc *FeedController) GetFeeds(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	feeds, err := models.FetchFeeds(roomID)
+	if err != nil {
+		fmt.Printf("フィード一覧の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "フィード一覧の取得に失敗しました",
+		})
+	}
+	if feeds == nil {
+		feeds = []models.Feed{}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"feeds": feeds,
+	})
+}
+
+// AddFeed フィードを登録し、最初の取り込みを行う
+// 登録前に一度取得して、RSSまたはAtomとして解析できるか確認する
+func (fc *FeedController) AddFeed(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	var req struct {
+		URL string `json:"url"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効なリクエストです",
+		})
+	}
+
+	feedURL := strings.TrimSpace(req.URL)
+	if _, err := models.NormalizeArticleURL(feedURL); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "フィードのURLが正しくありません",
+		})
+	}
+
+	feeds, err := models.FetchFeeds(roomID)
+	if err != nil {
+		fmt.Printf("フィード一覧の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "フィードの登録に失敗しました",
+		})
+	}
+	for _, f := range feeds {
+		if f.URL == feedURL {
+			return c.JSON(http.StatusConflict, map[string]string{
+				"message": "このフィードは登録済みです",
+			})
+		}
+	}
+
+	feed := models.Feed{RoomID: roomID, URL: feedURL}
+	parsed, err := fc.poller.Fetch(c.Request().Context(), &feed)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": fmt.Sprintf("フィードを読み込めませんでした: %v", err),
+		})
+	}
+
+	created, err := models.CreateFeed(feed)
+	if err != nil {
+		fmt.Printf("フィードの登録エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "フィードの登録に失敗しました",
+		})
+	}
+
+	// 次回以降の条件付きGETのために取得状態を保存する
+	created.ETag = feed.ETag
+	created.LastModified = feed.LastModified
+	now := time.Now().UTC()
+	created.LastFetchedAt = &now
+	if err := models.UpdateFeedFetchState(*created); err != nil {
+		fmt.Printf("フィードの取得状態の保存エラー: %v\n", err)
+	}
+
+	added, err := fc.poller.Ingest(c.Request().Context(), roomID, created.Title, parsed.Items)
+	if err != nil {
+		fmt.Printf("フィードの取り込みエラー: %v\n", err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": fmt.Sprintf("フィード「%s」を登録し、%d件の記事を取り込みました", created.Title, added),
+		"feed":    created,
+		"added":   added,
+	})
+}
+
+// DeleteFeed フィードの購読を解除（取り込み済みの記事は残す）
+func (fc *FeedController) DeleteFeed(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効なフィードIDです",
+		})
+	}
+
+	deleted, err := models.DeleteFeed(roomID, id)
+	if err != nil {
+		fmt.Printf("フィードの削除エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "フィードの削除に失敗しました",
+		})
+	}
+	if !deleted {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"message": "フィードが見つかりません",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "フィードを削除しました",
+	})
+}
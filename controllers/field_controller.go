@@ -10,6 +10,9 @@ import (
 	"os"
 	"strings"
 
+	"login-app/models"
+	"login-app/services"
+
 	"github.com/labstack/echo-contrib/session"
 	"github.com/labstack/echo/v4"
 )
@@ -65,8 +68,8 @@ func (fc *FieldController) GetFields(c echo.Context) error {
 	supabaseURL := os.Getenv("SUPABASE_URL")
 	supabaseKey := os.Getenv("SUPABASE_KEY")
 
-	// フィールド一覧を取得するクエリ（room_id, field_name, priorityを取得）
-	fullURL := fmt.Sprintf("%s/rest/v1/field?select=room_id,field_name,priority&room_id=eq.%s", supabaseURL, roomID)
+	// フィールド一覧を取得するクエリ（room_id, field_name, priorityを並び順で取得）
+	fullURL := fmt.Sprintf("%s/rest/v1/field?select=room_id,field_name,priority&room_id=eq.%s&order=sort_order.asc.nullslast,field_name.asc", supabaseURL, roomID)
 
 	req, err := http.NewRequest("GET", fullURL, nil)
 	if err != nil {
@@ -211,6 +214,7 @@ func (fc *FieldController) DeleteFields(c echo.Context) error {
 		})
 	}
 
+	rescoreArticles(roomID)
 	return c.JSON(http.StatusOK, map[string]string{
 		"message": fmt.Sprintf("%d個の分野を削除しました", successCount),
 	})
@@ -322,6 +326,7 @@ func (fc *FieldController) AddField(c echo.Context) error {
 		})
 	}
 
+	rescoreArticles(roomID)
 	message := fmt.Sprintf("%d個の分野を追加しました", addedCount)
 	return c.JSON(http.StatusOK, map[string]string{
 		"message": message,
@@ -423,7 +428,155 @@ func (fc *FieldController) UpdateFieldPriority(c echo.Context) error {
 		})
 	}
 
+	rescoreArticles(roomID)
 	return c.JSON(http.StatusOK, map[string]string{
 		"message": "優先度を更新しました",
 	})
 }
+
+// UpdateFieldPriorities 複数の分野の優先度と並び順をまとめて更新
+// fieldsで優先度を、orderで並び順（先頭から順に）を指定し、値が変わった分野だけを更新する
+func (fc *FieldController) UpdateFieldPriorities(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	// リクエストボディを解析
+	var req struct {
+		Fields []struct {
+			FieldName string `json:"field_name"`
+			Priority  int    `json:"priority"`
+		} `json:"fields"`
+		Order []string `json:"order"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効なリクエストです",
+		})
+	}
+
+	if len(req.Fields) == 0 && len(req.Order) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "更新する分野が指定されていません",
+		})
+	}
+
+	current, err := models.FetchFields(roomID)
+	if err != nil {
+		fmt.Printf("分野一覧の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "分野一覧の取得に失敗しました",
+		})
+	}
+
+	// 現在の分野を名前で引けるようにする
+	index := make(map[string]int, len(current))
+	for i, field := range current {
+		index[field.FieldName] = i
+	}
+
+	// 入力の検証（エラーはまとめて返す）
+	var errors []string
+	seen := make(map[string]bool)
+	for _, f := range req.Fields {
+		if _, ok := index[f.FieldName]; !ok {
+			errors = append(errors, fmt.Sprintf("分野「%s」は登録されていません", f.FieldName))
+			continue
+		}
+		if seen[f.FieldName] {
+			errors = append(errors, fmt.Sprintf("分野「%s」が重複しています", f.FieldName))
+			continue
+		}
+		seen[f.FieldName] = true
+		if !models.ValidPriority(f.Priority) {
+			errors = append(errors, fmt.Sprintf("分野「%s」の優先度は1から5の間で指定してください", f.FieldName))
+		}
+	}
+
+	seen = make(map[string]bool)
+	for _, name := range req.Order {
+		if _, ok := index[name]; !ok {
+			errors = append(errors, fmt.Sprintf("並び順の分野「%s」は登録されていません", name))
+			continue
+		}
+		if seen[name] {
+			errors = append(errors, fmt.Sprintf("並び順の分野「%s」が重複しています", name))
+		}
+		seen[name] = true
+	}
+
+	if len(errors) > 0 {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"message": "無効な分野が含まれています",
+			"errors":  errors,
+		})
+	}
+
+	// 優先度を反映
+	updated := append([]models.Field(nil), current...)
+	for _, f := range req.Fields {
+		updated[index[f.FieldName]].Priority = f.Priority
+	}
+
+	// 並び順を反映（指定されなかった分野は現在の順序のまま後ろに並べる）
+	if len(req.Order) > 0 {
+		position := 0
+		for _, name := range req.Order {
+			order := position
+			updated[index[name]].SortOrder = &order
+			position++
+		}
+		for i := range updated {
+			if !seen[updated[i].FieldName] {
+				order := position
+				updated[i].SortOrder = &order
+				position++
+			}
+		}
+	}
+
+	// 変わった列だけを分野ごとに更新し、処理中に削除された分野を作り直さないようにする
+	changed := 0
+	for i, field := range updated {
+		changes := make(map[string]interface{})
+		if field.Priority != current[i].Priority {
+			changes["priority"] = field.Priority
+		}
+		if !equalSortOrder(field.SortOrder, current[i].SortOrder) {
+			changes["sort_order"] = field.SortOrder
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		if err := models.UpdateField(roomID, field.FieldName, changes); err != nil {
+			fmt.Printf("一括更新エラー: %v\n", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"message": "優先度の一括更新に失敗しました",
+			})
+		}
+		changed++
+	}
+	if changed > 0 {
+		rescoreArticles(roomID)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": fmt.Sprintf("%d個の分野を更新しました", changed),
+	})
+}
+
+// rescoreArticles 分野を変更した後にルームの記事を採点し直す（失敗しても分野の変更は成功とする）
+func rescoreArticles(roomID string) {
+	if _, err := services.RescoreRoom(roomID); err != nil {
+		fmt.Printf("記事の再採点エラー - Room ID: %s, Error: %v\n", roomID, err)
+	}
+}
+
+// equalSortOrder 並び順が同じかどうか（どちらも未設定の場合も同じとする）
+func equalSortOrder(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
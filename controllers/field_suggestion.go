@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"login-app/models"
+	"login-app/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 分野の候補の件数
+const (
+	defaultSuggestionLimit = 20
+	maxSuggestionLimit     = 100
+	suggestionHistoryLimit = 500
+)
+
+// GetFieldSuggestions ルームの記事から頻出する語を分野の候補として返す
+// 予約中の記事と配信・削除済みの履歴（新しい順に最大suggestionHistoryLimit件）を対象にし、登録済みの分野は除く
+func (fc *FieldController) GetFieldSuggestions(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	limit := defaultSuggestionLimit
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxSuggestionLimit {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"message": fmt.Sprintf("limitは1から%dの間で指定してください", maxSuggestionLimit),
+			})
+		}
+		limit = n
+	}
+
+	articles, err := models.FetchArticles(roomID)
+	if err != nil {
+		fmt.Printf("記事一覧の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "記事一覧の取得に失敗しました",
+		})
+	}
+	histories, _, err := models.SearchArticleHistory(roomID, models.HistoryQuery{Limit: suggestionHistoryLimit})
+	if err != nil {
+		fmt.Printf("履歴の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "履歴の取得に失敗しました",
+		})
+	}
+	fields, err := models.FetchFields(roomID)
+	if err != nil {
+		fmt.Printf("分野一覧の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "分野一覧の取得に失敗しました",
+		})
+	}
+
+	// 予約中の記事が配信済みとして履歴にも残っている場合は1件として数える
+	seen := make(map[int64]bool, len(articles))
+	docs := make([]services.KeywordDocument, 0, len(articles)+len(histories))
+	for _, article := range articles {
+		content := models.ParseArticleContent(article.Content)
+		seen[article.ArticleID] = true
+		docs = append(docs, services.KeywordDocument{Title: content.Title, Text: content.Summary})
+	}
+	for _, history := range histories {
+		if seen[history.ArticleID] {
+			continue
+		}
+		docs = append(docs, services.KeywordDocument{Title: history.Title, Text: history.Summary})
+	}
+
+	suggestions := services.SuggestKeywords(docs, fields, limit)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"suggestions":   suggestions,
+		"article_count": len(docs),
+	})
+}
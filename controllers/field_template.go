@@ -0,0 +1,301 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"login-app/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CopyFields ログイン中のルームの分野（優先度を含む）を別のルームに複製
+func (fc *FieldController) CopyFields(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	var req struct {
+		TargetRoomID string `json:"target_room_id"`
+		Mode         string `json:"mode"`
+		DryRun       bool   `json:"dry_run"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効なリクエストです",
+		})
+	}
+
+	req.TargetRoomID = strings.TrimSpace(req.TargetRoomID)
+	if req.TargetRoomID == "" || req.TargetRoomID == roomID {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "コピー先のルームIDを指定してください",
+		})
+	}
+	if req.Mode == "" {
+		req.Mode = "merge"
+	}
+	if req.Mode != "merge" && req.Mode != "replace" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "modeはmergeまたはreplaceを指定してください",
+		})
+	}
+
+	// コピー先もログイン可能な登録済みルームに限る
+	if !models.RoomExists(req.TargetRoomID) {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"message": "コピー先のルームが見つかりません",
+		})
+	}
+
+	fields, err := models.FetchFields(roomID)
+	if err != nil {
+		fmt.Printf("分野一覧の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "分野一覧の取得に失敗しました",
+		})
+	}
+	if len(fields) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "コピーする分野がありません",
+		})
+	}
+
+	records := make([]fieldRecord, 0, len(fields))
+	for _, field := range fields {
+		records = append(records, fieldRecord{FieldName: field.FieldName, Priority: field.Priority})
+	}
+
+	return applyFieldRecords(c, req.TargetRoomID, records, req.Mode, req.DryRun)
+}
+
+// GetFieldTemplates 分野テンプレートの一覧を取得
+func (fc *FieldController) GetFieldTemplates(c echo.Context) error {
+	if _, err := getSessionRoomID(c); err != nil {
+		return err
+	}
+
+	templates, err := models.FetchFieldTemplates()
+	if err != nil {
+		fmt.Printf("テンプレート一覧の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "テンプレート一覧の取得に失敗しました",
+		})
+	}
+	if templates == nil {
+		templates = []models.FieldTemplate{}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"templates": templates,
+	})
+}
+
+// CreateFieldTemplate 分野テンプレートを作成
+// fieldsを省略した場合はログイン中のルームの分野をそのままテンプレートにする
+func (fc *FieldController) CreateFieldTemplate(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	var req struct {
+		Name   string                 `json:"name"`
+		Fields []models.TemplateField `json:"fields"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効なリクエストです",
+		})
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "テンプレート名が指定されていません",
+		})
+	}
+
+	if len(req.Fields) == 0 {
+		fields, err := models.FetchFields(roomID)
+		if err != nil {
+			fmt.Printf("分野一覧の取得エラー: %v\n", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"message": "分野一覧の取得に失敗しました",
+			})
+		}
+		for _, field := range fields {
+			req.Fields = append(req.Fields, models.TemplateField{FieldName: field.FieldName, Priority: field.Priority})
+		}
+	}
+
+	// 分野名を正規化し、優先度と重複を検証
+	var errors []string
+	seen := make(map[string]bool)
+	templateFields := make([]models.TemplateField, 0, len(req.Fields))
+	for _, f := range req.Fields {
+		name := models.NormalizeFieldName(f.FieldName)
+		if name == "" {
+			errors = append(errors, "分野名が空の項目があります")
+			continue
+		}
+		if seen[name] {
+			errors = append(errors, fmt.Sprintf("分野「%s」が重複しています", name))
+			continue
+		}
+		seen[name] = true
+		if f.Priority == 0 {
+			f.Priority = models.DefaultPriority
+		}
+		if !models.ValidPriority(f.Priority) {
+			errors = append(errors, fmt.Sprintf("分野「%s」の優先度は1から5の間で指定してください", name))
+			continue
+		}
+		templateFields = append(templateFields, models.TemplateField{FieldName: name, Priority: f.Priority})
+	}
+
+	if len(errors) > 0 {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"message": "無効な分野が含まれています",
+			"errors":  errors,
+		})
+	}
+	if len(templateFields) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "テンプレートに含める分野がありません",
+		})
+	}
+
+	template := models.FieldTemplate{
+		Name:            req.Name,
+		CreatedByRoomID: roomID,
+		Fields:          templateFields,
+	}
+	if err := models.CreateFieldTemplate(template); err != nil {
+		fmt.Printf("テンプレートの作成エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "テンプレートの作成に失敗しました（同じ名前のテンプレートがないか確認してください）",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": fmt.Sprintf("テンプレート「%s」を作成しました", req.Name),
+	})
+}
+
+// DeleteFieldTemplate 分野テンプレートを削除（作成したルームからのみ削除可能）
+func (fc *FieldController) DeleteFieldTemplate(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効なテンプレートIDです",
+		})
+	}
+
+	deleted, err := models.DeleteFieldTemplate(id, roomID)
+	if err != nil {
+		fmt.Printf("テンプレートの削除エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "テンプレートの削除に失敗しました",
+		})
+	}
+	if !deleted {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"message": "削除できるテンプレートが見つかりません",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "テンプレートを削除しました",
+	})
+}
+
+// ApplyFieldTemplate 分野テンプレートをログイン中のルームに適用
+func (fc *FieldController) ApplyFieldTemplate(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効なテンプレートIDです",
+		})
+	}
+
+	var req struct {
+		Mode   string `json:"mode"`
+		DryRun bool   `json:"dry_run"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効なリクエストです",
+		})
+	}
+	if req.Mode == "" {
+		req.Mode = "merge"
+	}
+	if req.Mode != "merge" && req.Mode != "replace" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "modeはmergeまたはreplaceを指定してください",
+		})
+	}
+
+	template, err := models.FetchFieldTemplate(id)
+	if err != nil {
+		fmt.Printf("テンプレートの取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "テンプレートの取得に失敗しました",
+		})
+	}
+	if template == nil || len(template.Fields) == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"message": "テンプレートが見つかりません",
+		})
+	}
+
+	records := make([]fieldRecord, 0, len(template.Fields))
+	for _, f := range template.Fields {
+		records = append(records, fieldRecord{FieldName: f.FieldName, Priority: f.Priority})
+	}
+
+	return applyFieldRecords(c, roomID, records, req.Mode, req.DryRun)
+}
+
+// applyFieldRecords 分野の一覧をルームに反映し、変更内容を返す（dryRunの場合はプレビューのみ）
+func applyFieldRecords(c echo.Context, roomID string, records []fieldRecord, mode string, dryRun bool) error {
+	plan, err := planFieldChanges(roomID, records, mode)
+	if err != nil {
+		fmt.Printf("分野一覧の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "分野一覧の取得に失敗しました",
+		})
+	}
+
+	result := plan.summary()
+	result["dry_run"] = dryRun
+
+	if dryRun {
+		result["message"] = "反映内容のプレビューです"
+		return c.JSON(http.StatusOK, result)
+	}
+
+	if err := plan.apply(); err != nil {
+		fmt.Printf("分野の反映エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "分野の反映に失敗しました",
+		})
+	}
+
+	result["message"] = plan.resultMessage()
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,415 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"login-app/models"
+	"login-app/services"
+
+	"github.com/labstack/echo/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// インポートファイルの最大サイズ
+const maxImportSize = 1 << 20
+
+// fieldRecord インポート・エクスポートで扱う分野の1行
+type fieldRecord struct {
+	FieldName string `json:"field_name" yaml:"field_name"`
+	Priority  int    `json:"priority" yaml:"priority"`
+}
+
+// fieldDocument JSON/YAMLのエクスポート形式
+type fieldDocument struct {
+	Fields []fieldRecord `json:"fields" yaml:"fields"`
+}
+
+// rowError 行ごとの検証エラー
+type rowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// ExportFields ルームの分野をCSV/JSON/YAMLで出力
+func (fc *FieldController) ExportFields(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = "json"
+	}
+
+	fields, err := models.FetchFields(roomID)
+	if err != nil {
+		fmt.Printf("分野一覧の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "分野一覧の取得に失敗しました",
+		})
+	}
+
+	doc := fieldDocument{Fields: make([]fieldRecord, 0, len(fields))}
+	for _, field := range fields {
+		doc.Fields = append(doc.Fields, fieldRecord{FieldName: field.FieldName, Priority: field.Priority})
+	}
+
+	var buf bytes.Buffer
+	var contentType string
+	switch format {
+	case "csv":
+		contentType = "text/csv; charset=UTF-8"
+		w := csv.NewWriter(&buf)
+		w.Write([]string{"field_name", "priority"})
+		for _, f := range doc.Fields {
+			w.Write([]string{services.CSVText(f.FieldName), strconv.Itoa(f.Priority)})
+		}
+		w.Flush()
+		err = w.Error()
+	case "json":
+		contentType = echo.MIMEApplicationJSONCharsetUTF8
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(doc)
+	case "yaml":
+		contentType = "application/yaml; charset=UTF-8"
+		err = yaml.NewEncoder(&buf).Encode(doc)
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "formatはcsv、json、yamlのいずれかを指定してください",
+		})
+	}
+	if err != nil {
+		fmt.Printf("エクスポートの生成エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "エクスポートの生成に失敗しました",
+		})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="fields-%s.%s"`, roomID, format))
+	return c.Blob(http.StatusOK, contentType, buf.Bytes())
+}
+
+// ImportFields CSV/JSON/YAMLから分野を取り込む
+// mode=merge は既存の分野を残したまま追加・更新し、mode=replace はファイルにない分野を削除する
+// dry_run=true の場合は変更内容のプレビューのみを返す
+func (fc *FieldController) ImportFields(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	mode := c.QueryParam("mode")
+	if mode == "" {
+		mode = "merge"
+	}
+	if mode != "merge" && mode != "replace" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "modeはmergeまたはreplaceを指定してください",
+		})
+	}
+	dryRun := c.QueryParam("dry_run") == "true"
+
+	data, filename, err := readImportBody(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	// 形式はクエリ、ファイル拡張子の順で判定する
+	format := c.QueryParam("format")
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+		if format == "yml" {
+			format = "yaml"
+		}
+	}
+
+	records, rowErrors, err := parseFieldRecords(format, data)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	if len(rowErrors) == 0 && len(records) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "インポートする分野がありません",
+		})
+	}
+
+	plan, err := planFieldChanges(roomID, records, mode)
+	if err != nil {
+		fmt.Printf("分野一覧の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "分野一覧の取得に失敗しました",
+		})
+	}
+
+	preview := plan.summary()
+	preview["dry_run"] = dryRun
+	preview["errors"] = rowErrors
+
+	if dryRun {
+		preview["message"] = "インポート内容のプレビューです"
+		return c.JSON(http.StatusOK, preview)
+	}
+
+	// エラーのある行が含まれる場合は何も反映しない
+	if len(rowErrors) > 0 {
+		preview["message"] = "無効な行が含まれているためインポートを中止しました"
+		return c.JSON(http.StatusBadRequest, preview)
+	}
+
+	if err := plan.apply(); err != nil {
+		fmt.Printf("インポートエラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "分野のインポートに失敗しました",
+		})
+	}
+
+	preview["message"] = plan.resultMessage()
+	return c.JSON(http.StatusOK, preview)
+}
+
+// fieldChangePlan 分野の一覧をルームに反映する際の変更内容
+type fieldChangePlan struct {
+	roomID    string
+	mode      string
+	added     []string
+	updated   []string
+	unchanged []string
+	removed   []string
+	upserts   []models.Field
+	keep      []string
+}
+
+// planFieldChanges 現在の分野と比較して変更内容を計算する
+// mode=merge は既存の分野を残し、mode=replace はrecordsにない分野を削除対象にする
+func planFieldChanges(roomID string, records []fieldRecord, mode string) (*fieldChangePlan, error) {
+	current, err := models.FetchFields(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]models.Field, len(current))
+	for _, field := range current {
+		existing[field.FieldName] = field
+	}
+
+	plan := &fieldChangePlan{roomID: roomID, mode: mode}
+	imported := make(map[string]bool, len(records))
+	nextOrder := len(current)
+	for _, r := range records {
+		imported[r.FieldName] = true
+		plan.keep = append(plan.keep, r.FieldName)
+		field, ok := existing[r.FieldName]
+		switch {
+		case !ok:
+			plan.added = append(plan.added, r.FieldName)
+			order := nextOrder
+			plan.upserts = append(plan.upserts, models.Field{RoomID: roomID, FieldName: r.FieldName, Priority: r.Priority, SortOrder: &order})
+			nextOrder++
+		case field.Priority != r.Priority:
+			plan.updated = append(plan.updated, r.FieldName)
+			field.Priority = r.Priority
+			plan.upserts = append(plan.upserts, field)
+		default:
+			plan.unchanged = append(plan.unchanged, r.FieldName)
+		}
+	}
+	if mode == "replace" {
+		for _, field := range current {
+			if !imported[field.FieldName] {
+				plan.removed = append(plan.removed, field.FieldName)
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// summary プレビュー用の変更内容
+func (p *fieldChangePlan) summary() map[string]interface{} {
+	return map[string]interface{}{
+		"mode":      p.mode,
+		"added":     nonNil(p.added),
+		"updated":   nonNil(p.updated),
+		"unchanged": nonNil(p.unchanged),
+		"removed":   nonNil(p.removed),
+	}
+}
+
+// resultMessage 反映結果のメッセージ
+func (p *fieldChangePlan) resultMessage() string {
+	return fmt.Sprintf("追加%d件、更新%d件、削除%d件を反映しました", len(p.added), len(p.updated), len(p.removed))
+}
+
+// apply 変更内容を反映する
+// 追加・更新は1回のupsertで行い、その後に置き換えで不要になった分野を削除して記事を採点し直す
+func (p *fieldChangePlan) apply() error {
+	if err := models.UpsertFields(p.upserts); err != nil {
+		return err
+	}
+	if len(p.removed) > 0 {
+		if err := models.DeleteFieldsExcept(p.roomID, p.keep); err != nil {
+			return fmt.Errorf("不要な分野の削除に失敗しました: %w", err)
+		}
+	}
+	rescoreArticles(p.roomID)
+	return nil
+}
+
+// readImportBody multipartの"file"、またはリクエストボディそのものを読み込む
+func readImportBody(c echo.Context) ([]byte, string, error) {
+	if file, err := c.FormFile("file"); err == nil {
+		src, err := file.Open()
+		if err != nil {
+			return nil, "", fmt.Errorf("ファイルを開けませんでした")
+		}
+		defer src.Close()
+
+		data, err := io.ReadAll(io.LimitReader(src, maxImportSize+1))
+		if err != nil {
+			return nil, "", fmt.Errorf("ファイルの読み込みに失敗しました")
+		}
+		if len(data) > maxImportSize {
+			return nil, "", fmt.Errorf("ファイルサイズが大きすぎます")
+		}
+		return data, file.Filename, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(c.Request().Body, maxImportSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("リクエストの読み込みに失敗しました")
+	}
+	if len(data) > maxImportSize {
+		return nil, "", fmt.Errorf("ファイルサイズが大きすぎます")
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, "", fmt.Errorf("インポートするファイルが指定されていません")
+	}
+	return data, "", nil
+}
+
+// parseFieldRecords 形式に応じて分野の行を読み込み、行ごとに検証する
+// ファイル全体が読めない場合のみerrorを返し、個々の行の問題はrowErrorとして返す
+func parseFieldRecords(format string, data []byte) ([]fieldRecord, []rowError, error) {
+	type rawRecord struct {
+		row      int
+		name     string
+		priority string
+	}
+	var raws []rawRecord
+
+	switch format {
+	case "csv":
+		r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+		r.FieldsPerRecord = -1
+		rows, err := r.ReadAll()
+		if err != nil {
+			return nil, nil, fmt.Errorf("CSVの解析に失敗しました: %v", err)
+		}
+		for i, row := range rows {
+			if i == 0 && len(row) > 0 && strings.TrimSpace(row[0]) == "field_name" {
+				continue
+			}
+			raw := rawRecord{row: i + 1}
+			if len(row) > 0 {
+				raw.name = services.ParseCSVText(row[0])
+			}
+			if len(row) > 1 {
+				raw.priority = strings.TrimSpace(row[1])
+			}
+			raws = append(raws, raw)
+		}
+	case "json", "yaml":
+		// {"fields": [...]} と配列そのものの両方を受け付ける
+		var items []map[string]interface{}
+		var doc struct {
+			Fields []map[string]interface{} `json:"fields" yaml:"fields"`
+		}
+		var err error
+		if format == "json" {
+			if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+				err = json.Unmarshal(data, &items)
+			} else {
+				err = json.Unmarshal(data, &doc)
+				items = doc.Fields
+			}
+		} else {
+			if err = yaml.Unmarshal(data, &items); err != nil {
+				err = yaml.Unmarshal(data, &doc)
+				items = doc.Fields
+			}
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%sの解析に失敗しました: %v", strings.ToUpper(format), err)
+		}
+		for i, item := range items {
+			raw := rawRecord{row: i + 1}
+			if name, ok := item["field_name"]; ok && name != nil {
+				raw.name = fmt.Sprint(name)
+			}
+			if priority, ok := item["priority"]; ok && priority != nil {
+				raw.priority = fmt.Sprint(priority)
+			}
+			raws = append(raws, raw)
+		}
+	default:
+		return nil, nil, fmt.Errorf("formatはcsv、json、yamlのいずれかを指定してください")
+	}
+
+	var records []fieldRecord
+	var rowErrors []rowError
+	seen := make(map[string]int)
+	for _, raw := range raws {
+		name := models.NormalizeFieldName(raw.name)
+		if name == "" {
+			rowErrors = append(rowErrors, rowError{Row: raw.row, Message: "分野名が空です"})
+			continue
+		}
+
+		priority := models.DefaultPriority
+		if raw.priority != "" {
+			p, err := strconv.Atoi(raw.priority)
+			if err != nil || !models.ValidPriority(p) {
+				rowErrors = append(rowErrors, rowError{Row: raw.row, Message: fmt.Sprintf("分野「%s」の優先度は1から5の間で指定してください", name)})
+				continue
+			}
+			priority = p
+		}
+
+		if first, ok := seen[name]; ok {
+			rowErrors = append(rowErrors, rowError{Row: raw.row, Message: fmt.Sprintf("分野「%s」は%d行目と重複しています", name, first)})
+			continue
+		}
+		seen[name] = raw.row
+
+		records = append(records, fieldRecord{FieldName: name, Priority: priority})
+	}
+
+	if rowErrors == nil {
+		rowErrors = []rowError{}
+	}
+	return records, rowErrors, nil
+}
+
+// nonNil JSONでnullではなく空配列を返すための変換
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
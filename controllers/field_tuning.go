@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"login-app/models"
+	"login-app/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetFieldTuning 分野ごとの反応の集計と優先度の変更案を取得
+// 自動調整のモードがoffの場合は集計だけを返す
+func (fc *FieldController) GetFieldTuning(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	report, err := services.BuildFieldTuningReport(roomID, time.Now().UTC())
+	if err != nil {
+		fmt.Printf("反応の集計エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "反応の集計に失敗しました",
+		})
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// UpdateFieldTuning 優先度の自動調整のモードを更新
+func (fc *FieldController) UpdateFieldTuning(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	var req struct {
+		Mode string `json:"mode"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効なリクエストです",
+		})
+	}
+	if !models.ValidTuningMode(req.Mode) {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "modeにはoff、propose、applyのいずれかを指定してください",
+		})
+	}
+
+	if err := models.SaveFieldTuningMode(roomID, req.Mode); err != nil {
+		fmt.Printf("自動調整の設定の保存エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "自動調整の設定の保存に失敗しました",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "自動調整の設定を更新しました",
+	})
+}
+
+// ApplyFieldTuning 優先度の変更案を反映
+// field_namesを指定した場合はその分野の変更案だけを反映する（省略した場合はすべて）
+func (fc *FieldController) ApplyFieldTuning(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	var req struct {
+		FieldNames []string `json:"field_names"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効なリクエストです",
+		})
+	}
+
+	// 変更案は送られた値ではなく、現在の反応から作り直したものを使う
+	report, err := services.BuildFieldTuningReport(roomID, time.Now().UTC())
+	if err != nil {
+		fmt.Printf("反応の集計エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "反応の集計に失敗しました",
+		})
+	}
+	if report.Mode == models.TuningModeOff {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "自動調整がオフになっています",
+		})
+	}
+
+	proposals := report.Proposals
+	if len(req.FieldNames) > 0 {
+		selected := make(map[string]bool, len(req.FieldNames))
+		for _, name := range req.FieldNames {
+			selected[name] = true
+		}
+		proposals = nil
+		for _, p := range report.Proposals {
+			if selected[p.FieldName] {
+				proposals = append(proposals, p)
+			}
+		}
+	}
+	if len(proposals) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "反映できる変更案がありません",
+		})
+	}
+
+	changes, err := services.ApplyPriorityProposals(roomID, proposals, false)
+	if err != nil {
+		fmt.Printf("優先度の反映エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "優先度の反映に失敗しました",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": fmt.Sprintf("%d件の分野の優先度を変更しました", len(changes)),
+		"changes": changes,
+	})
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"net/http"
+
+	"login-app/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// LinkCheckController 記事のリンク切れの確認に関するコントローラー
+type LinkCheckController struct {
+	checker *services.LinkChecker
+}
+
+// NewLinkCheckController コントローラーのインスタンスを作成
+func NewLinkCheckController(checker *services.LinkChecker) *LinkCheckController {
+	return &LinkCheckController{checker: checker}
+}
+
+// CheckLinks ルームの未配信の記事のリンクの確認をバックグラウンドで開始する
+// 確認には時間がかかるため202を返し、画面はGetLinkCheckStatusと記事一覧のlinkの絞り込みで結果を確かめる
+func (lc *LinkCheckController) CheckLinks(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	message := "リンクの確認を開始しました"
+	if !lc.checker.StartRoomCheck(roomID) {
+		message = "リンクを確認中です"
+	}
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"message": message,
+		"running": true,
+	})
+}
+
+// GetLinkCheckStatus ルームのリンクの確認が実行中かどうかを取得
+func (lc *LinkCheckController) GetLinkCheckStatus(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"running": lc.checker.RoomCheckRunning(roomID),
+	})
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"login-app/models"
+	"login-app/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetRetention ルームの予約記事の保持ルールを取得
+func (rc *RoomSettingsController) GetRetention(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	rule, err := models.FetchRetentionRule(roomID)
+	if err != nil {
+		fmt.Printf("保持ルールの取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "保持ルールの取得に失敗しました",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"rule": rule,
+	})
+}
+
+// UpdateRetention ルームの予約記事の保持ルールを更新
+func (rc *RoomSettingsController) UpdateRetention(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	var rule models.RetentionRule
+	if err := c.Bind(&rule); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効なリクエストです",
+		})
+	}
+	rule.RoomID = roomID
+
+	if err := services.ValidateRetentionRule(rule); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	if err := models.SaveRetentionRule(rule); err != nil {
+		fmt.Printf("保持ルールの保存エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "保持ルールの保存に失敗しました",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "保持ルールを保存しました",
+		"rule":    rule,
+	})
+}
+
+// DryRunRetention 保持ルールを今適用した場合に削除される記事を取得（削除はしない）
+func (rc *RoomSettingsController) DryRunRetention(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	candidates, err := services.PruneRoom(roomID, true, time.Now().UTC())
+	if err != nil {
+		fmt.Printf("保持ルールの確認エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "保持ルールの確認に失敗しました",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":  fmt.Sprintf("%d件の記事が削除されます", len(candidates)),
+		"articles": candidates,
+	})
+}
+
+// PruneRetention 保持ルールを今すぐ適用して記事を削除
+func (rc *RoomSettingsController) PruneRetention(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	pruned, err := services.PruneRoom(roomID, false, time.Now().UTC())
+	if err != nil {
+		fmt.Printf("保持ルールの適用エラー: %v\n", err)
+		if len(pruned) == 0 {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"message": "保持ルールの適用に失敗しました",
+			})
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":  fmt.Sprintf("%d件の記事を削除しました", len(pruned)),
+		"articles": pruned,
+	})
+}
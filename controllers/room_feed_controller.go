@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"login-app/models"
+	"login-app/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RoomFeedController ルームの記事を購読フィードとして公開するコントローラー
+type RoomFeedController struct {
+	links *services.LinkSigner
+}
+
+// NewRoomFeedController コントローラーのインスタンスを作成
+func NewRoomFeedController(links *services.LinkSigner) *RoomFeedController {
+	return &RoomFeedController{links: links}
+}
+
+// ShowFeed ルームの記事をAtom（.atom）またはJSON Feed 1.1（.json）で返す
+// フィードリーダーから開かれるためログインは不要で、ルームに発行した秘密の値がURLに含まれる場合だけ返す
+// statusにreservedまたはdeliveredを指定すると予約記事・配信済みの記事だけにする
+func (rc *RoomFeedController) ShowFeed(c echo.Context) error {
+	roomID := c.Param("room")
+	file := c.Param("file")
+
+	secret, isAtom := strings.CutSuffix(file, ".atom")
+	if !isAtom {
+		var isJSON bool
+		if secret, isJSON = strings.CutSuffix(file, ".json"); !isJSON {
+			return c.String(http.StatusNotFound, "フィードが見つかりません")
+		}
+	}
+
+	status := c.QueryParam("status")
+	if status != "" && status != services.RoomFeedStatusReserved && status != services.RoomFeedStatusDelivered {
+		return c.String(http.StatusBadRequest, "statusにはreservedまたはdeliveredを指定してください")
+	}
+
+	stored, err := models.FetchRoomFeedSecret(roomID)
+	if err != nil {
+		fmt.Printf("フィードの秘密の値の取得エラー: %v\n", err)
+		return c.String(http.StatusInternalServerError, "フィードの取得に失敗しました")
+	}
+	// 発行していないルームと値が違う場合を区別しない
+	if !services.VerifyFeedSecret(stored, secret) {
+		return c.String(http.StatusNotFound, "フィードが見つかりません")
+	}
+
+	baseURL := publicBaseURL(c)
+	items, err := services.BuildRoomFeed(roomID, status, func(articleID int64, articleURL string) string {
+		return baseURL + rc.links.Path(services.ClickTarget{
+			RoomID:    roomID,
+			ArticleID: articleID,
+			URL:       articleURL,
+			Source:    models.ClickSourceFeed,
+		})
+	})
+	if err != nil {
+		fmt.Printf("フィードの記事の取得エラー: %v\n", err)
+		return c.String(http.StatusInternalServerError, "フィードの取得に失敗しました")
+	}
+
+	feed := services.RoomFeed{
+		RoomID:  roomID,
+		Title:   fmt.Sprintf("ルーム %s の記事", roomID),
+		HomeURL: baseURL + "/articles",
+		FeedURL: baseURL + c.Request().URL.RequestURI(),
+		Updated: time.Now().UTC(),
+		Items:   items,
+	}
+	if len(items) > 0 {
+		feed.Updated = items[0].Published
+	}
+
+	// URLに秘密の値を含むため、リンク先に送らず検索エンジンにも登録させない
+	c.Response().Header().Set("Referrer-Policy", "no-referrer")
+	c.Response().Header().Set("X-Robots-Tag", "noindex")
+	c.Response().Header().Set("Cache-Control", "private, max-age=300")
+
+	if isAtom {
+		c.Response().Header().Set(echo.HeaderContentType, "application/atom+xml; charset=utf-8")
+		c.Response().WriteHeader(http.StatusOK)
+		return services.WriteAtom(c.Response(), feed)
+	}
+	c.Response().Header().Set(echo.HeaderContentType, "application/feed+json; charset=utf-8")
+	c.Response().WriteHeader(http.StatusOK)
+	return services.WriteJSONFeed(c.Response(), feed)
+}
+
+// GetFeedSecret ルームの購読フィードを公開しているかどうかを取得
+func (rc *RoomFeedController) GetFeedSecret(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	stored, err := models.FetchRoomFeedSecret(roomID)
+	if err != nil {
+		fmt.Printf("フィードの秘密の値の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "購読フィードの設定の取得に失敗しました",
+		})
+	}
+	if stored == nil {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"enabled": false,
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"enabled":    true,
+		"created_at": stored.CreatedAt,
+	})
+}
+
+// CreateFeedSecret 購読フィードのURLを発行する（発行済みの場合は以前のURLを使えなくする）
+// 秘密の値は保存しないため、URLはこのときだけ返す
+func (rc *RoomFeedController) CreateFeedSecret(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	secret, err := services.GenerateFeedSecret()
+	if err != nil {
+		fmt.Printf("フィードの秘密の値の作成エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "購読フィードのURLの発行に失敗しました",
+		})
+	}
+
+	if err := models.SaveRoomFeedSecret(models.RoomFeedSecret{
+		RoomID:     roomID,
+		SecretHash: services.HashFeedSecret(secret),
+		CreatedAt:  time.Now().UTC(),
+	}); err != nil {
+		fmt.Printf("フィードの秘密の値の保存エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "購読フィードのURLの発行に失敗しました",
+		})
+	}
+
+	prefix := fmt.Sprintf("%s/feeds/%s/%s", publicBaseURL(c), url.PathEscape(roomID), secret)
+	return c.JSON(http.StatusOK, map[string]string{
+		"message":  "購読フィードのURLを発行しました",
+		"atom_url": prefix + ".atom",
+		"json_url": prefix + ".json",
+	})
+}
+
+// DeleteFeedSecret 購読フィードの公開をやめる（発行したURLは使えなくなる）
+func (rc *RoomFeedController) DeleteFeedSecret(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := models.DeleteRoomFeedSecret(roomID); err != nil {
+		fmt.Printf("フィードの秘密の値の削除エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "購読フィードの公開の停止に失敗しました",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "購読フィードの公開を停止しました",
+	})
+}
+
+// publicBaseURL 外部から開くURLの起点（PUBLIC_BASE_URLが設定されていない場合はリクエストから求める）
+func publicBaseURL(c echo.Context) string {
+	if base := os.Getenv("PUBLIC_BASE_URL"); base != "" {
+		return strings.TrimSuffix(base, "/")
+	}
+	return c.Scheme() + "://" + c.Request().Host
+}
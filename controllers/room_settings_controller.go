@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"login-app/models"
+	"login-app/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RoomSettingsController ルームごとの配信設定に関するコントローラー
+type RoomSettingsController struct{}
+
+// NewRoomSettingsController コントローラーのインスタンスを作成
+func NewRoomSettingsController() *RoomSettingsController {
+	return &RoomSettingsController{}
+}
+
+// ShowSettings 配信設定ページを表示
+func (rc *RoomSettingsController) ShowSettings(c echo.Context) error {
+	if _, err := getSessionRoomID(c); err != nil {
+		return c.Redirect(http.StatusSeeOther, "/")
+	}
+
+	return c.File("views/settings.html")
+}
+
+// GetSettings ルームの配信設定を取得（未設定の場合は既定値を返す）
+func (rc *RoomSettingsController) GetSettings(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	settings, err := models.FetchRoomSettings(roomID)
+	if err != nil {
+		fmt.Printf("配信設定の取得エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "配信設定の取得に失敗しました",
+		})
+	}
+	if settings == nil {
+		defaults := services.DefaultRoomSettings()
+		defaults.RoomID = roomID
+		settings = &defaults
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"settings": settings,
+	})
+}
+
+// UpdateSettings ルームの配信設定を更新
+func (rc *RoomSettingsController) UpdateSettings(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	var settings models.RoomSettings
+	if err := c.Bind(&settings); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "無効なリクエストです",
+		})
+	}
+	settings.RoomID = roomID
+
+	if settings.TimeZone == "" {
+		settings.TimeZone = services.DefaultDeliveryTimeZone
+	}
+	if len(settings.DeliveryCron) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "配信時刻を1つ以上指定してください",
+		})
+	}
+	if settings.QuietPeriods == nil {
+		settings.QuietPeriods = []models.QuietPeriod{}
+	}
+
+	// 保存前にスケジュールとして解釈できるか検証する
+	schedule, err := services.ScheduleFromSettings(settings)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	// cron形式と配信停止期間は正規化した文字列で保存する
+	for i, cron := range schedule.Crons {
+		settings.DeliveryCron[i] = cron.String()
+	}
+	settings.QuietPeriods = schedule.QuietPeriods
+
+	if err := models.SaveRoomSettings(settings); err != nil {
+		fmt.Printf("配信設定の保存エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "配信設定の保存に失敗しました",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":  "配信設定を保存しました",
+		"settings": settings,
+	})
+}
@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo-contrib/session"
+	"github.com/labstack/echo/v4"
+)
+
+// getSessionRoomID セッションを検証してroom_idを取得
+// 失敗時は {"message": ...} 形式で返却されるecho.HTTPErrorを返す
+func getSessionRoomID(c echo.Context) (string, error) {
+	sess, err := session.Get("login-session", c)
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusUnauthorized, "セッションが無効です")
+	}
+
+	// セッションの検証
+	auth, ok := sess.Values["authenticated"].(bool)
+	if !ok || !auth {
+		return "", echo.NewHTTPError(http.StatusUnauthorized, "認証が必要です")
+	}
+
+	roomID, ok := sess.Values["room_id"].(string)
+	if !ok {
+		return "", echo.NewHTTPError(http.StatusInternalServerError, "room_idの取得に失敗しました")
+	}
+
+	return roomID, nil
+}
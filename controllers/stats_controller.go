@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"login-app/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// StatsController 記事の予約・配信・クリックの統計に関するコントローラー
+type StatsController struct{}
+
+// NewStatsController コントローラーのインスタンスを作成
+func NewStatsController() *StatsController {
+	return &StatsController{}
+}
+
+// ShowStats 統計ページを表示
+func (sc *StatsController) ShowStats(c echo.Context) error {
+	if _, err := getSessionRoomID(c); err != nil {
+		return c.Redirect(http.StatusSeeOther, "/")
+	}
+
+	return c.File("views/stats.html")
+}
+
+// GetStats 予約・配信・クリックの件数を週ごとに取得
+func (sc *StatsController) GetStats(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	weeks, ok := parseStatsWeeks(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": fmt.Sprintf("weeksは1から%dの間で指定してください", services.MaxStatsWeeks),
+		})
+	}
+
+	stats, err := services.BuildRoomStats(roomID, weeks, time.Now())
+	if err != nil {
+		fmt.Printf("統計の集計エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "統計の集計に失敗しました",
+		})
+	}
+
+	return c.JSON(http.StatusOK, stats)
+}
+
+// GetFieldStats 分野ごとの一致した記事・配信・クリック・反応の件数を取得
+func (sc *StatsController) GetFieldStats(c echo.Context) error {
+	roomID, err := getSessionRoomID(c)
+	if err != nil {
+		return err
+	}
+
+	weeks, ok := parseStatsWeeks(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": fmt.Sprintf("weeksは1から%dの間で指定してください", services.MaxStatsWeeks),
+		})
+	}
+
+	report, err := services.BuildFieldStats(roomID, weeks, time.Now())
+	if err != nil {
+		fmt.Printf("分野ごとの統計の集計エラー: %v\n", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "分野ごとの統計の集計に失敗しました",
+		})
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// parseStatsWeeks 集計する週数を取得（未指定の場合は既定値）
+func parseStatsWeeks(c echo.Context) (int, bool) {
+	v := c.QueryParam("weeks")
+	if v == "" {
+		return services.DefaultStatsWeeks, true
+	}
+	weeks, err := strconv.Atoi(v)
+	if err != nil || weeks < 1 || weeks > services.MaxStatsWeeks {
+		return 0, false
+	}
+	return weeks, true
+}
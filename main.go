@@ -1,10 +1,16 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
+	_ "time/tzdata" // Alpineのイメージでもタイムゾーンを読み込めるようにする
 
+	"login-app/chatwork"
 	"login-app/controllers"
+	"login-app/services"
+	"login-app/summarizer"
 	"net/http"
 
 	"github.com/gorilla/sessions"
@@ -36,10 +42,25 @@ func main() {
 	}
 	e.Use(session.Middleware(sessions.NewCookieStore([]byte(sessionSecret))))
 
+	// 記事のリンクのクリックを記録する転送用リンクの署名（開発用の既定のセッションの鍵は使わない）
+	linkSigner := services.LinkSignerFromEnv(os.Getenv("SESSION_SECRET"))
+
 	// コントローラーの初期化
 	authController := controllers.NewAuthController()
 	fieldController := controllers.NewFieldController()
-	articleController := controllers.NewArticleController()
+	// 利用者が指定したURLの取得には内部ネットワークに接続しないクライアントを使う
+	fetchClient := services.NewSafeHTTPClient(30 * time.Second)
+	metadataFetcher := services.NewMetadataFetcher(fetchClient)
+	articleSummarizer := services.NewArticleSummarizer(newSummarizer(), metadataFetcher)
+	articleController := controllers.NewArticleController(metadataFetcher, services.NewURLCanonicalizer(fetchClient), articleSummarizer, linkSigner)
+	roomSettingsController := controllers.NewRoomSettingsController()
+	feedPoller := services.NewFeedPoller(fetchClient, articleSummarizer, services.FeedPollInterval())
+	feedController := controllers.NewFeedController(feedPoller)
+	clickController := controllers.NewClickController(linkSigner)
+	statsController := controllers.NewStatsController()
+	linkChecker := services.NewLinkChecker(fetchClient, services.LinkCheckInterval())
+	linkCheckController := controllers.NewLinkCheckController(linkChecker)
+	roomFeedController := controllers.NewRoomFeedController(linkSigner)
 
 	// 静的ファイルの提供（削除）
 
@@ -55,11 +76,74 @@ func main() {
 	e.DELETE("/api/fields", fieldController.DeleteFields, authController.RequireAuth)
 	e.POST("/api/fields", fieldController.AddField, authController.RequireAuth)
 	e.PUT("/api/fields/priority", fieldController.UpdateFieldPriority, authController.RequireAuth)
+	e.PUT("/api/fields/priorities", fieldController.UpdateFieldPriorities, authController.RequireAuth)
+	e.GET("/api/fields/tuning", fieldController.GetFieldTuning, authController.RequireAuth)
+	e.PUT("/api/fields/tuning", fieldController.UpdateFieldTuning, authController.RequireAuth)
+	e.POST("/api/fields/tuning/apply", fieldController.ApplyFieldTuning, authController.RequireAuth)
+	e.GET("/api/fields/suggestions", fieldController.GetFieldSuggestions, authController.RequireAuth)
+	e.GET("/api/fields/export", fieldController.ExportFields, authController.RequireAuth)
+	e.POST("/api/fields/import", fieldController.ImportFields, authController.RequireAuth)
+	e.POST("/api/fields/copy", fieldController.CopyFields, authController.RequireAuth)
+	e.GET("/api/field-templates", fieldController.GetFieldTemplates, authController.RequireAuth)
+	e.POST("/api/field-templates", fieldController.CreateFieldTemplate, authController.RequireAuth)
+	e.DELETE("/api/field-templates/:id", fieldController.DeleteFieldTemplate, authController.RequireAuth)
+	e.POST("/api/field-templates/:id/apply", fieldController.ApplyFieldTemplate, authController.RequireAuth)
 
 	// 記事一覧関連のルーティング
 	e.GET("/articles", articleController.ShowArticles, authController.RequireAuth)
+	e.GET("/archive", articleController.ShowArchive, authController.RequireAuth)
 	e.GET("/api/articles", articleController.GetArticles, authController.RequireAuth)
+	e.GET("/api/articles/history", articleController.GetArticleHistory, authController.RequireAuth)
+	e.GET("/api/articles/export", articleController.ExportArticles, authController.RequireAuth)
+	e.POST("/api/articles", articleController.CreateArticle, authController.RequireAuth)
+	e.GET("/api/metadata", articleController.GetURLMetadata, authController.RequireAuth)
+	e.GET("/api/articles/duplicates", articleController.GetDuplicateArticles, authController.RequireAuth)
+	e.POST("/api/articles/duplicates/merge", articleController.MergeDuplicateArticles, authController.RequireAuth)
+	e.POST("/api/articles/enrich", articleController.EnrichArticles, authController.RequireAuth)
+	e.POST("/api/articles/:id/summarize", articleController.SummarizeArticle, authController.RequireAuth)
+	e.POST("/api/articles/:id/enrich", articleController.EnrichArticle, authController.RequireAuth)
 	e.DELETE("/api/articles", articleController.DeleteArticles, authController.RequireAuth)
+	e.DELETE("/api/articles/broken", articleController.DeleteBrokenArticles, authController.RequireAuth)
+	e.POST("/api/articles/links/check", linkCheckController.CheckLinks, authController.RequireAuth)
+	e.GET("/api/articles/links/check", linkCheckController.GetLinkCheckStatus, authController.RequireAuth)
+	e.PUT("/api/articles/queue", articleController.ReorderArticles, authController.RequireAuth)
+	e.PUT("/api/articles/:id/pin", articleController.PinArticle, authController.RequireAuth)
+	e.DELETE("/api/articles/:id/pin", articleController.UnpinArticle, authController.RequireAuth)
+	e.PATCH("/api/articles/:id", articleController.UpdateArticle, authController.RequireAuth)
+	e.GET("/api/articles/:id/preview", articleController.PreviewArticle, authController.RequireAuth)
+	e.GET("/api/articles/:id/edits", articleController.GetArticleEdits, authController.RequireAuth)
+	e.PUT("/api/articles/:id/state", articleController.UpdateArticleState, authController.RequireAuth)
+	e.POST("/api/articles/:id/feedback", articleController.RecordFeedback, authController.RequireAuth)
+
+	// 配信設定関連のルーティング
+	e.GET("/settings", roomSettingsController.ShowSettings, authController.RequireAuth)
+	e.GET("/api/room-settings", roomSettingsController.GetSettings, authController.RequireAuth)
+	e.PUT("/api/room-settings", roomSettingsController.UpdateSettings, authController.RequireAuth)
+	e.GET("/api/retention", roomSettingsController.GetRetention, authController.RequireAuth)
+	e.PUT("/api/retention", roomSettingsController.UpdateRetention, authController.RequireAuth)
+	e.GET("/api/retention/dry-run", roomSettingsController.DryRunRetention, authController.RequireAuth)
+	e.POST("/api/retention/prune", roomSettingsController.PruneRetention, authController.RequireAuth)
+
+	// フィード購読関連のルーティング
+	e.GET("/api/feeds", feedController.GetFeeds, authController.RequireAuth)
+	e.POST("/api/feeds", feedController.AddFeed, authController.RequireAuth)
+	e.DELETE("/api/feeds/:id", feedController.DeleteFeed, authController.RequireAuth)
+
+	// 購読フィードの公開関連のルーティング
+	e.GET("/api/feed-secret", roomFeedController.GetFeedSecret, authController.RequireAuth)
+	e.POST("/api/feed-secret", roomFeedController.CreateFeedSecret, authController.RequireAuth)
+	e.DELETE("/api/feed-secret", roomFeedController.DeleteFeedSecret, authController.RequireAuth)
+
+	// 統計関連のルーティング
+	e.GET("/stats", statsController.ShowStats, authController.RequireAuth)
+	e.GET("/api/stats", statsController.GetStats, authController.RequireAuth)
+	e.GET("/api/stats/fields", statsController.GetFieldStats, authController.RequireAuth)
+
+	// 記事のリンクのクリックの記録（配信したメッセージから開かれるため認証なし）
+	e.GET("/r/:token", clickController.Redirect)
+
+	// ルームの記事の購読フィード（フィードリーダーから開かれるため認証なし、URLの秘密の値で保護する）
+	e.GET("/feeds/:room/:file", roomFeedController.ShowFeed)
 
 	e.GET("/keepalive", func(c echo.Context) error {
 		return c.String(http.StatusOK, "alive!")
@@ -68,6 +152,31 @@ func main() {
 		return c.NoContent(http.StatusOK)
 	})
 
+	// 予約記事のChatwork配信（APIトークンが設定されている場合のみ）
+	if token := os.Getenv("CHATWORK_API_TOKEN"); token != "" {
+		schedule, err := services.DefaultDeliverySchedule()
+		if err != nil {
+			log.Fatalf("配信スケジュールの設定エラー: %v", err)
+		}
+		client := chatwork.NewAPIClient(os.Getenv("CHATWORK_API_URL"), token)
+		scheduler := services.NewDeliveryScheduler(client, services.RoomDeliveryScheduleFunc(schedule), linkSigner)
+		go scheduler.Run(context.Background())
+	} else {
+		log.Println("Warning: CHATWORK_API_TOKEN is not set, article delivery is disabled")
+	}
+
+	// 登録されたRSS/Atomフィードの定期取り込み
+	go feedPoller.Run(context.Background())
+
+	// 未配信の記事のリンク切れの確認
+	go linkChecker.Run(context.Background())
+
+	// 保持ルールによる予約記事の定期的な削除
+	go services.NewRetentionPruner(services.DefaultRetentionInterval).Run(context.Background())
+
+	// 反応をもとにした分野の優先度の自動調整
+	go services.NewFieldTuner().Run(context.Background())
+
 	// ポート番号の設定
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -78,6 +187,22 @@ func main() {
 	e.Logger.Fatal(e.Start(":" + port))
 }
 
+// newSummarizer 要約に使うSummarizerを作成
+// LLM_API_URLが設定されている場合はLLMで要約し、失敗した場合は抽出型の要約を使う
+func newSummarizer() summarizer.Summarizer {
+	extractive := summarizer.NewExtractive(summarizer.DefaultSummarySentences)
+
+	llmURL := os.Getenv("LLM_API_URL")
+	if llmURL == "" {
+		return extractive
+	}
+	model := os.Getenv("LLM_MODEL")
+	if model == "" {
+		model = "gpt-4o-mini"
+	}
+	return summarizer.WithFallback(summarizer.NewLLMClient(llmURL, os.Getenv("LLM_API_KEY"), model), extractive)
+}
+
 // 認証ミドルウェア
 func requireAuth(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -0,0 +1,28 @@
+-- 分野の並び順と一括upsert用の一意制約
+ALTER TABLE field ADD COLUMN IF NOT EXISTS sort_order integer;
+
+-- 重複を確認せずに登録していた分野があるため、同じルーム・分野名の行はidの最も小さいものだけを残してから制約を追加する
+-- （idの列がない場合は物理的に先にある行を残す）
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'field_room_id_field_name_key' AND conrelid = 'field'::regclass
+    ) THEN
+        IF EXISTS (
+            SELECT 1 FROM information_schema.columns
+            WHERE table_schema = current_schema() AND table_name = 'field' AND column_name = 'id'
+        ) THEN
+            DELETE FROM field AS f
+            USING field AS keep
+            WHERE f.room_id = keep.room_id AND f.field_name = keep.field_name AND f.id > keep.id;
+        ELSE
+            DELETE FROM field AS f
+            USING field AS keep
+            WHERE f.room_id = keep.room_id AND f.field_name = keep.field_name AND f.ctid > keep.ctid;
+        END IF;
+
+        ALTER TABLE field
+            ADD CONSTRAINT field_room_id_field_name_key UNIQUE (room_id, field_name);
+    END IF;
+END $$;
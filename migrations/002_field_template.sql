@@ -0,0 +1,14 @@
+-- ルーム間で共有する分野テンプレート
+CREATE TABLE IF NOT EXISTS field_template (
+    id                 bigserial PRIMARY KEY,
+    name               text NOT NULL UNIQUE,
+    created_by_room_id text NOT NULL,
+    fields             jsonb NOT NULL DEFAULT '[]',
+    created_at         timestamptz NOT NULL DEFAULT now()
+);
+
+-- 初期テンプレート（分野名はadmin.htmlの正規化規則に合わせる）
+INSERT INTO field_template (name, created_by_room_id, fields) VALUES
+    ('バックエンドチーム', 'system', '[{"field_name":"Go","priority":5},{"field_name":"Python","priority":4},{"field_name":"Postgresql","priority":4},{"field_name":"Docker","priority":3},{"field_name":"Kubernetes","priority":3}]'),
+    ('フロントエンドチーム', 'system', '[{"field_name":"Typescript","priority":5},{"field_name":"React","priority":5},{"field_name":"Css","priority":4},{"field_name":"Next.js","priority":3},{"field_name":"アクセシビリティ","priority":3}]')
+ON CONFLICT (name) DO NOTHING;
@@ -0,0 +1,10 @@
+-- 記事ごとの既読・スター・メモ
+CREATE TABLE IF NOT EXISTS article_state (
+    room_id    text    NOT NULL,
+    article_id bigint  NOT NULL REFERENCES reserve_article (article_id) ON DELETE CASCADE,
+    is_read    boolean NOT NULL DEFAULT false,
+    is_starred boolean NOT NULL DEFAULT false,
+    note       text    NOT NULL DEFAULT '',
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (room_id, article_id)
+);
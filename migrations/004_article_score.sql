@@ -0,0 +1,4 @@
+-- 登録分野に対する記事の関連度スコア
+ALTER TABLE reserve_article ADD COLUMN IF NOT EXISTS score double precision NOT NULL DEFAULT 0;
+ALTER TABLE reserve_article ADD COLUMN IF NOT EXISTS matched_fields jsonb NOT NULL DEFAULT '[]';
+ALTER TABLE reserve_article ADD COLUMN IF NOT EXISTS scored_at timestamptz;
@@ -0,0 +1,18 @@
+-- Chatworkへの配信状態
+ALTER TABLE reserve_article ADD COLUMN IF NOT EXISTS delivered_at timestamptz;
+ALTER TABLE reserve_article ADD COLUMN IF NOT EXISTS delivery_attempts integer NOT NULL DEFAULT 0;
+ALTER TABLE reserve_article ADD COLUMN IF NOT EXISTS next_retry_at timestamptz;
+ALTER TABLE reserve_article ADD COLUMN IF NOT EXISTS last_delivery_error text NOT NULL DEFAULT '';
+
+-- 配信の試行ごとの記録（記事が削除されても残す）
+CREATE TABLE IF NOT EXISTS delivery_log (
+    id           bigserial PRIMARY KEY,
+    room_id      text        NOT NULL,
+    article_id   bigint      NOT NULL,
+    status       text        NOT NULL,
+    message_id   text        NOT NULL DEFAULT '',
+    error        text        NOT NULL DEFAULT '',
+    attempted_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS delivery_log_room_id_attempted_at_idx ON delivery_log (room_id, attempted_at);
@@ -0,0 +1,10 @@
+-- ルームごとの配信設定
+CREATE TABLE IF NOT EXISTS room_settings (
+    room_id          text PRIMARY KEY,
+    delivery_enabled boolean     NOT NULL DEFAULT true,
+    timezone         text        NOT NULL DEFAULT 'Asia/Tokyo',
+    delivery_cron    jsonb       NOT NULL DEFAULT '["0 9 * * *"]',
+    max_articles     integer     NOT NULL DEFAULT 3 CHECK (max_articles BETWEEN 1 AND 20),
+    quiet_periods    jsonb       NOT NULL DEFAULT '[]',
+    updated_at       timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,17 @@
+-- 配信・削除された記事の履歴
+CREATE TABLE IF NOT EXISTS article_history (
+    room_id        text             NOT NULL,
+    article_id     bigint           NOT NULL,
+    title          text             NOT NULL DEFAULT '',
+    url            text             NOT NULL DEFAULT '',
+    summary        text             NOT NULL DEFAULT '',
+    content        text             NOT NULL,
+    matched_fields jsonb            NOT NULL DEFAULT '[]',
+    score          double precision NOT NULL DEFAULT 0,
+    delivered_at   timestamptz,
+    deleted_at     timestamptz,
+    recorded_at    timestamptz      NOT NULL DEFAULT now(),
+    PRIMARY KEY (room_id, article_id)
+);
+
+CREATE INDEX IF NOT EXISTS article_history_room_id_recorded_at_idx ON article_history (room_id, recorded_at DESC);
@@ -0,0 +1,13 @@
+-- ルームが購読するRSS/Atomフィード
+CREATE TABLE IF NOT EXISTS feed (
+    id              bigserial PRIMARY KEY,
+    room_id         text        NOT NULL,
+    url             text        NOT NULL,
+    title           text        NOT NULL DEFAULT '',
+    etag            text        NOT NULL DEFAULT '',
+    last_modified   text        NOT NULL DEFAULT '',
+    last_fetched_at timestamptz,
+    last_error      text        NOT NULL DEFAULT '',
+    created_at      timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (room_id, url)
+);
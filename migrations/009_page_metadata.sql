@@ -0,0 +1,11 @@
+-- 記事URLから取得したページのメタデータ（OpenGraphなど）のキャッシュ
+CREATE TABLE IF NOT EXISTS page_metadata (
+    url           text        PRIMARY KEY,
+    title         text        NOT NULL DEFAULT '',
+    description   text        NOT NULL DEFAULT '',
+    image         text        NOT NULL DEFAULT '',
+    canonical_url text        NOT NULL DEFAULT '',
+    published_at  timestamptz,
+    error         text        NOT NULL DEFAULT '',
+    fetched_at    timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,35 @@
+-- 記事への反応（👍・👎・クリック）
+-- 記事が配信・削除されても集計できるよう、反応した時点の一致した分野も記録する
+CREATE TABLE IF NOT EXISTS article_feedback (
+    id             bigserial PRIMARY KEY,
+    room_id        text        NOT NULL,
+    article_id     bigint      NOT NULL,
+    signal         text        NOT NULL CHECK (signal IN ('up', 'down', 'clear', 'click')),
+    matched_fields jsonb       NOT NULL DEFAULT '[]',
+    created_at     timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS article_feedback_room_id_created_at_idx ON article_feedback (room_id, created_at);
+
+-- ルームごとの優先度の自動調整の設定
+-- off: 調整しない、propose: 変更案を表示する、apply: 変更案を定期的に自動で反映する
+CREATE TABLE IF NOT EXISTS field_tuning (
+    room_id     text        PRIMARY KEY,
+    mode        text        NOT NULL DEFAULT 'off' CHECK (mode IN ('off', 'propose', 'apply')),
+    last_run_at timestamptz,
+    updated_at  timestamptz NOT NULL DEFAULT now()
+);
+
+-- 反応をもとに変更した優先度の記録
+CREATE TABLE IF NOT EXISTS field_priority_change (
+    id           bigserial PRIMARY KEY,
+    room_id      text        NOT NULL,
+    field_name   text        NOT NULL,
+    old_priority integer     NOT NULL,
+    new_priority integer     NOT NULL,
+    reason       text        NOT NULL DEFAULT '',
+    automatic    boolean     NOT NULL DEFAULT false,
+    created_at   timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS field_priority_change_room_id_created_at_idx ON field_priority_change (room_id, created_at DESC);
@@ -0,0 +1,13 @@
+-- 記事のリンクのクリック（/r/{token} の転送を通ったもの）
+-- 利用者を特定しないよう、User-Agentはブラウザ・OSの種類だけを記録する
+CREATE TABLE IF NOT EXISTS article_click (
+    id         bigserial PRIMARY KEY,
+    room_id    text        NOT NULL,
+    article_id bigint      NOT NULL,
+    url        text        NOT NULL,
+    source     text        NOT NULL DEFAULT 'web' CHECK (source IN ('chatwork', 'web')),
+    user_agent text        NOT NULL DEFAULT '',
+    clicked_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS article_click_room_id_clicked_at_idx ON article_click (room_id, clicked_at);
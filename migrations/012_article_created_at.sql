@@ -0,0 +1,6 @@
+-- 記事を予約した時刻（週ごとの集計に使う）
+-- 既存の記事には追加した時点の時刻が入る
+ALTER TABLE reserve_article ADD COLUMN IF NOT EXISTS created_at timestamptz NOT NULL DEFAULT now();
+ALTER TABLE article_history ADD COLUMN IF NOT EXISTS reserved_at timestamptz;
+
+CREATE INDEX IF NOT EXISTS delivery_log_room_id_status_attempted_at_idx ON delivery_log (room_id, status, attempted_at);
@@ -0,0 +1,6 @@
+-- 記事URLのリンク切れの確認結果
+-- link_statusは最後に確認したHTTPステータス（接続できなかった場合は0）、link_failuresは連続して失敗した回数
+ALTER TABLE reserve_article ADD COLUMN IF NOT EXISTS link_status integer;
+ALTER TABLE reserve_article ADD COLUMN IF NOT EXISTS link_error text NOT NULL DEFAULT '';
+ALTER TABLE reserve_article ADD COLUMN IF NOT EXISTS link_failures integer NOT NULL DEFAULT 0;
+ALTER TABLE reserve_article ADD COLUMN IF NOT EXISTS link_checked_at timestamptz;
@@ -0,0 +1,10 @@
+-- ルームごとの予約記事の保持ルール（0は無制限）
+CREATE TABLE IF NOT EXISTS retention_rule (
+    room_id               text PRIMARY KEY,
+    max_age_days          integer     NOT NULL DEFAULT 0 CHECK (max_age_days >= 0),
+    max_count             integer     NOT NULL DEFAULT 0 CHECK (max_count >= 0),
+    delete_after_delivery boolean     NOT NULL DEFAULT false,
+    delivered_keep_days   integer     NOT NULL DEFAULT 0 CHECK (delivered_keep_days >= 0),
+    last_pruned_at        timestamptz,
+    updated_at            timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,13 @@
+-- 手動で外した分野（本文に含まれていても一致したものとして扱わない）
+ALTER TABLE reserve_article ADD COLUMN IF NOT EXISTS detached_fields jsonb NOT NULL DEFAULT '[]';
+
+-- 記事の編集の記録
+CREATE TABLE IF NOT EXISTS article_edit (
+    id         bigserial PRIMARY KEY,
+    room_id    text        NOT NULL,
+    article_id bigint      NOT NULL,
+    changes    jsonb       NOT NULL,
+    edited_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS article_edit_room_id_article_id_idx ON article_edit (room_id, article_id, edited_at DESC);
@@ -0,0 +1,3 @@
+-- 予約記事の配信順（手動で並べ替えた位置と固定）
+ALTER TABLE reserve_article ADD COLUMN IF NOT EXISTS queue_position integer;
+ALTER TABLE reserve_article ADD COLUMN IF NOT EXISTS pinned boolean NOT NULL DEFAULT false;
@@ -0,0 +1,6 @@
+-- ルームの記事を購読フィードとして公開するための秘密の値（SHA-256のハッシュだけを保存する）
+CREATE TABLE IF NOT EXISTS room_feed_secret (
+    room_id     text PRIMARY KEY,
+    secret_hash text        NOT NULL,
+    created_at  timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,4 @@
+-- 購読フィードからのクリックを記録できるようにする
+ALTER TABLE article_click DROP CONSTRAINT IF EXISTS article_click_source_check;
+ALTER TABLE article_click
+    ADD CONSTRAINT article_click_source_check CHECK (source IN ('chatwork', 'web', 'feed'));
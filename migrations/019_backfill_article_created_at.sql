@@ -0,0 +1,14 @@
+-- 012で created_at を追加する前からある記事には追加した時点の時刻が入っているため、
+-- 採点・配信の記録のうち最も早い時刻で置き換える（予約した時刻より後になるが、保持期間の判定には十分近い）
+-- 記録のない記事は追加した時点の時刻のまま残る
+UPDATE reserve_article AS a
+SET created_at = LEAST(a.created_at, a.scored_at, a.delivered_at, (
+    SELECT min(l.attempted_at)
+    FROM delivery_log AS l
+    WHERE l.room_id = a.room_id AND l.article_id = a.article_id
+))
+WHERE LEAST(a.scored_at, a.delivered_at, (
+    SELECT min(l.attempted_at)
+    FROM delivery_log AS l
+    WHERE l.room_id = a.room_id AND l.article_id = a.article_id
+)) < a.created_at;
@@ -0,0 +1,6 @@
+-- ルームごとに最後に配信した枠（再起動や複数インスタンスで同じ枠を二重に配信しないため）
+CREATE TABLE IF NOT EXISTS delivery_slot (
+    room_id    text PRIMARY KEY,
+    slot_at    timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,185 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Article reserve_articleテーブルの記事
+type Article struct {
+	ArticleID     int64      `json:"article_id"`
+	RoomID        string     `json:"room_id"`
+	Content       string     `json:"content"`
+	Score         float64    `json:"score"`
+	MatchedFields []string   `json:"matched_fields"`
+	ScoredAt      *time.Time `json:"scored_at"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+
+	// 手動で外した分野（本文に含まれていても一致したものとして扱わない）
+	DetachedFields []string `json:"detached_fields"`
+
+	// 配信順（固定した記事が先頭、次に並べ替えた位置の順。位置がない記事はスコア順）
+	QueuePosition *int `json:"queue_position"`
+	Pinned        bool `json:"pinned"`
+
+	// 配信状態
+	DeliveredAt       *time.Time `json:"delivered_at"`
+	DeliveryAttempts  int        `json:"delivery_attempts"`
+	NextRetryAt       *time.Time `json:"next_retry_at"`
+	LastDeliveryError string     `json:"last_delivery_error"`
+
+	// リンク切れの確認結果
+	LinkStatus    *int       `json:"link_status"`
+	LinkError     string     `json:"link_error"`
+	LinkFailures  int        `json:"link_failures"`
+	LinkCheckedAt *time.Time `json:"link_checked_at"`
+
+	// 履歴から読み込んだ記事を削除した時刻（reserve_articleの記事にはない）
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// FetchArticles ルームIDに紐づく記事一覧を取得
+func FetchArticles(roomID string) ([]Article, error) {
+	path := "reserve_article?select=*&room_id=eq." + url.QueryEscape(roomID)
+
+	var articles []Article
+	if err := supabaseSelect(path, &articles); err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
+// FetchArticle ルーム内の記事を1件取得（存在しない場合はnil）
+func FetchArticle(roomID string, articleID int64) (*Article, error) {
+	path := fmt.Sprintf("reserve_article?select=*&article_id=eq.%d&room_id=eq.%s", articleID, url.QueryEscape(roomID))
+
+	var articles []Article
+	if err := supabaseSelect(path, &articles); err != nil {
+		return nil, err
+	}
+	if len(articles) == 0 {
+		return nil, nil
+	}
+	return &articles[0], nil
+}
+
+// CreateArticle 記事を登録し、採番された記事を返す
+func CreateArticle(roomID, content string) (*Article, error) {
+	body, err := supabaseRequest("POST", "reserve_article", map[string]interface{}{
+		"room_id": roomID,
+		"content": content,
+	}, "return=representation")
+	if err != nil {
+		return nil, err
+	}
+
+	var created []Article
+	if err := json.Unmarshal(body, &created); err != nil {
+		return nil, fmt.Errorf("JSONのパースに失敗しました: %w", err)
+	}
+	if len(created) == 0 {
+		return nil, fmt.Errorf("登録した記事が返されませんでした")
+	}
+	return &created[0], nil
+}
+
+// FindArticleByURL ルーム内で正規化後のURLが一致する記事を探す（見つからない場合はnil）
+// URLは本文に埋め込まれているため、ルームの記事を解析して比較する
+func FindArticleByURL(roomID, normalizedURL string) (*Article, error) {
+	articles, err := FetchArticles(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range articles {
+		parsed := ParseArticleContent(articles[i].Content)
+		if parsed.URL == "" {
+			continue
+		}
+		if normalized, err := NormalizeArticleURL(parsed.URL); err == nil && normalized == normalizedURL {
+			return &articles[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdateArticleScores 記事のスコアと一致した分野を保存する
+// 記事ごとにスコアの列だけを更新し、同時に行われた編集や削除を上書き・復元しないようにする
+func UpdateArticleScores(articles []Article) error {
+	for _, a := range articles {
+		path := fmt.Sprintf("reserve_article?article_id=eq.%d&room_id=eq.%s", a.ArticleID, url.QueryEscape(a.RoomID))
+		if _, err := supabaseRequest("PATCH", path, map[string]interface{}{
+			"score":          a.Score,
+			"matched_fields": a.MatchedFields,
+			"scored_at":      a.ScoredAt,
+		}, "return=minimal"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateArticleQueue orderの記事に先頭から順に配信順の位置を付ける
+// それ以外の未配信の記事は位置を外してスコア順に戻す。固定は同時に行われた変更を戻さないよう書き込まない
+func UpdateArticleQueue(roomID string, order []int64) error {
+	ids := make([]string, len(order))
+	for i, id := range order {
+		ids[i] = strconv.FormatInt(id, 10)
+	}
+	path := fmt.Sprintf("reserve_article?room_id=eq.%s&delivered_at=is.null&queue_position=not.is.null&article_id=not.in.(%s)",
+		url.QueryEscape(roomID), strings.Join(ids, ","))
+	if _, err := supabaseRequest("PATCH", path, map[string]interface{}{
+		"queue_position": nil,
+	}, "return=minimal"); err != nil {
+		return err
+	}
+
+	for i, id := range order {
+		path := fmt.Sprintf("reserve_article?article_id=eq.%d&room_id=eq.%s", id, url.QueryEscape(roomID))
+		if _, err := supabaseRequest("PATCH", path, map[string]interface{}{
+			"queue_position": i,
+		}, "return=minimal"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateArticle 記事の指定した列を更新する
+func UpdateArticle(articleID int64, changes map[string]interface{}) error {
+	_, err := supabaseRequest("PATCH", fmt.Sprintf("reserve_article?article_id=eq.%d", articleID), changes, "return=minimal")
+	return err
+}
+
+// UpdateArticleIfUnchanged 本文がoldContentのままの場合だけ記事を更新する
+// 読み込んだ後にほかの操作で本文が変わっていた場合は更新せずにfalseを返す
+func UpdateArticleIfUnchanged(roomID string, articleID int64, oldContent string, changes map[string]interface{}) (bool, error) {
+	path := fmt.Sprintf("reserve_article?article_id=eq.%d&room_id=eq.%s&content=eq.%s",
+		articleID, url.QueryEscape(roomID), url.QueryEscape(oldContent))
+	body, err := supabaseRequest("PATCH", path, changes, "return=representation")
+	if err != nil {
+		return false, err
+	}
+	return returnedRows(body)
+}
+
+// DeleteArticle ルーム内の記事を1件削除する
+func DeleteArticle(roomID string, articleID int64) error {
+	path := fmt.Sprintf("reserve_article?article_id=eq.%d&room_id=eq.%s", articleID, url.QueryEscape(roomID))
+	_, err := supabaseRequest("DELETE", path, nil, "return=minimal")
+	return err
+}
+
+// UpdateArticleLinkCheck リンク切れの確認結果を保存する
+func UpdateArticleLinkCheck(articleID int64, status int, message string, failures int, checkedAt time.Time) error {
+	return UpdateArticle(articleID, map[string]interface{}{
+		"link_status":     status,
+		"link_error":      message,
+		"link_failures":   failures,
+		"link_checked_at": checkedAt,
+	})
+}
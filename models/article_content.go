@@ -0,0 +1,121 @@
+package models
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ArticleContent reserve_article.contentを解析した内容
+// articles.htmlのparseArticleContentと同じ規則で解析する
+type ArticleContent struct {
+	Field   string   `json:"field"`
+	Title   string   `json:"title"`
+	URL     string   `json:"url"`
+	Summary string   `json:"summary"`
+	Tags    []string `json:"tags"`
+}
+
+var (
+	contentFieldRegex   = regexp.MustCompile(`分野「(.+?)」の記事`)
+	contentTitleRegex   = regexp.MustCompile(`(?s)\[title\].*?\[/title\](.*?)https?://`)
+	contentURLRegex     = regexp.MustCompile(`https?://[^\s\[]+`)
+	contentSummaryRegex = regexp.MustCompile(`(?s)([^\[]*?)タグ:`)
+	contentTagsRegex    = regexp.MustCompile(`タグ: ([^\[]+)`)
+)
+
+// ParseArticleContent Chatwork記法の記事本文から分野・タイトル・URL・要約・タグを取り出す
+func ParseArticleContent(content string) ArticleContent {
+	var result ArticleContent
+
+	if m := contentFieldRegex.FindStringSubmatch(content); m != nil {
+		result.Field = m[1]
+	}
+
+	if m := contentTitleRegex.FindStringSubmatch(content); m != nil {
+		result.Title = strings.TrimSpace(m[1])
+	}
+
+	if loc := contentURLRegex.FindStringIndex(content); loc != nil {
+		result.URL = content[loc[0]:loc[1]]
+
+		// 要約はURLの後ろからタグまでのテキスト
+		if m := contentSummaryRegex.FindStringSubmatch(content[loc[1]:]); m != nil {
+			result.Summary = strings.TrimSpace(m[1])
+		}
+	}
+
+	if m := contentTagsRegex.FindStringSubmatch(content); m != nil {
+		for _, tag := range strings.Split(m[1], ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				result.Tags = append(result.Tags, tag)
+			}
+		}
+	}
+
+	return result
+}
+
+// RenderArticleContent 記事の内容をChatwork記法の本文に変換する
+// ParseArticleContentとarticles.htmlで読み戻せるように、要約とタグには "[" を含めない
+func RenderArticleContent(a ArticleContent) string {
+	field := a.Field
+	if field == "" {
+		field = "手動追加"
+	}
+
+	var b strings.Builder
+	b.WriteString("[info][title]分野「")
+	b.WriteString(sanitizeContentText(field))
+	b.WriteString("」の記事[/title]")
+	b.WriteString(sanitizeContentLine(a.Title))
+	b.WriteString("\n")
+	b.WriteString(escapeContentURL(a.URL))
+	b.WriteString("\n")
+	if summary := strings.TrimSpace(sanitizeContentText(a.Summary)); summary != "" {
+		b.WriteString(summary)
+		b.WriteString("\n")
+	}
+	b.WriteString("タグ: ")
+	tags := make([]string, 0, len(a.Tags))
+	for _, tag := range a.Tags {
+		if tag = sanitizeContentLine(strings.ReplaceAll(tag, ",", "、")); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	b.WriteString(strings.Join(tags, ", "))
+	b.WriteString("[/info]")
+
+	return b.String()
+}
+
+// escapeContentURL URL中の "[" "]" と空白をパーセントエンコードする
+// 記法のタグを差し込まれたり、URLの途中で区切られて残りが要約に混ざったりしないようにする
+func escapeContentURL(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '[', r == ']', unicode.IsSpace(r):
+			b.WriteString(url.PathEscape(string(r)))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// sanitizeContentText 記法の区切りと誤認される文字を置き換える
+func sanitizeContentText(s string) string {
+	s = strings.ReplaceAll(s, "[", "［")
+	s = strings.ReplaceAll(s, "]", "］")
+	s = strings.ReplaceAll(s, "タグ:", "タグ：")
+	return s
+}
+
+// sanitizeContentLine 1行に収める必要がある項目（タイトル・タグ）を整形する
+// タイトル中のURLが記事URLと誤認されないように "://" も置き換える
+func sanitizeContentLine(s string) string {
+	s = strings.ReplaceAll(sanitizeContentText(s), "://", ":／／")
+	return strings.Join(strings.Fields(s), " ")
+}
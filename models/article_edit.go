@@ -0,0 +1,41 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ArticleChange 編集で変わった項目の変更前と変更後
+type ArticleChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// ArticleEdit 記事の編集の記録
+// Changesのキーは "title"、"url"、"summary"、"fields"、"detached_fields"
+type ArticleEdit struct {
+	ID        int64                    `json:"id,omitempty"`
+	RoomID    string                   `json:"room_id"`
+	ArticleID int64                    `json:"article_id"`
+	Changes   map[string]ArticleChange `json:"changes"`
+	EditedAt  time.Time                `json:"edited_at"`
+}
+
+// RecordArticleEdit 記事の編集を記録する
+func RecordArticleEdit(edit ArticleEdit) error {
+	_, err := supabaseRequest("POST", "article_edit", edit, "return=minimal")
+	return err
+}
+
+// FetchArticleEdits 記事の編集の記録を新しい順に取得
+func FetchArticleEdits(roomID string, articleID int64) ([]ArticleEdit, error) {
+	path := fmt.Sprintf("article_edit?select=*&room_id=eq.%s&article_id=eq.%d&order=edited_at.desc,id.desc",
+		url.QueryEscape(roomID), articleID)
+
+	var edits []ArticleEdit
+	if err := supabaseSelect(path, &edits); err != nil {
+		return nil, err
+	}
+	return edits, nil
+}
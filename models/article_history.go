@@ -0,0 +1,132 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// 履歴に残す操作
+const (
+	HistoryEventDelivered = "delivered"
+	HistoryEventDeleted   = "deleted"
+)
+
+// ArticleHistory reserve_articleから配信・削除された記事の履歴
+// 記事1件につき1行で、配信時刻と削除時刻をそれぞれ記録する
+type ArticleHistory struct {
+	RoomID        string     `json:"room_id"`
+	ArticleID     int64      `json:"article_id"`
+	Title         string     `json:"title"`
+	URL           string     `json:"url"`
+	Summary       string     `json:"summary"`
+	Content       string     `json:"content"`
+	MatchedFields []string   `json:"matched_fields"`
+	Score         float64    `json:"score"`
+	DeliveredAt   *time.Time `json:"delivered_at"`
+	DeletedAt     *time.Time `json:"deleted_at"`
+	ReservedAt    *time.Time `json:"reserved_at"`
+	RecordedAt    time.Time  `json:"recorded_at"`
+}
+
+// HistoryQuery 履歴の検索条件
+type HistoryQuery struct {
+	Keyword string // タイトル・URL・要約の部分一致
+	Event   string // "delivered" または "deleted"（空の場合はすべて）
+	Limit   int
+	Offset  int
+}
+
+// ArchiveArticle 記事の内容とeventの時刻を履歴に記録する
+func ArchiveArticle(article Article, event string, at time.Time) error {
+	parsed := ParseArticleContent(article.Content)
+	matched := article.MatchedFields
+	if matched == nil {
+		matched = []string{}
+	}
+
+	row := map[string]interface{}{
+		"room_id":        article.RoomID,
+		"article_id":     article.ArticleID,
+		"title":          parsed.Title,
+		"url":            parsed.URL,
+		"summary":        parsed.Summary,
+		"content":        article.Content,
+		"matched_fields": matched,
+		"score":          article.Score,
+		"recorded_at":    at,
+	}
+	if article.CreatedAt != nil {
+		row["reserved_at"] = article.CreatedAt
+	}
+	switch event {
+	case HistoryEventDelivered:
+		row["delivered_at"] = at
+	case HistoryEventDeleted:
+		row["deleted_at"] = at
+	default:
+		return fmt.Errorf("不明な履歴の種類です: %s", event)
+	}
+
+	_, err := supabaseRequest("POST", "article_history?on_conflict=room_id,article_id", row,
+		"resolution=merge-duplicates,return=minimal")
+	return err
+}
+
+// SearchArticleHistory ルームの履歴を新しい順に検索し、条件に一致する全件数とともに返す
+func SearchArticleHistory(roomID string, q HistoryQuery) ([]ArticleHistory, int, error) {
+	path := fmt.Sprintf("article_history?select=*&room_id=eq.%s&order=recorded_at.desc&limit=%d&offset=%d",
+		url.QueryEscape(roomID), q.Limit, q.Offset)
+
+	switch q.Event {
+	case HistoryEventDelivered:
+		path += "&delivered_at=not.is.null"
+	case HistoryEventDeleted:
+		path += "&deleted_at=not.is.null"
+	}
+
+	if keyword := strings.TrimSpace(q.Keyword); keyword != "" {
+		pattern := quoteFilterValue(likePattern(keyword))
+		path += "&or=" + url.QueryEscape(fmt.Sprintf("(title.ilike.%s,url.ilike.%s,summary.ilike.%s)", pattern, pattern, pattern))
+	}
+
+	var histories []ArticleHistory
+	total, err := supabaseSelectPage(path, &histories)
+	if err != nil {
+		return nil, 0, err
+	}
+	return histories, total, nil
+}
+
+// FetchHistoryURLs ルームの履歴に残っている記事のURLを取得
+func FetchHistoryURLs(roomID string) ([]string, error) {
+	var rows []struct {
+		URL string `json:"url"`
+	}
+	if err := supabaseSelect("article_history?select=url&room_id=eq."+url.QueryEscape(roomID), &rows); err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if row.URL != "" {
+			urls = append(urls, row.URL)
+		}
+	}
+	return urls, nil
+}
+
+// FetchArticleHistory ルームの履歴から記事を1件取得（存在しない場合はnil）
+func FetchArticleHistory(roomID string, articleID int64) (*ArticleHistory, error) {
+	path := fmt.Sprintf("article_history?select=*&room_id=eq.%s&article_id=eq.%d", url.QueryEscape(roomID), articleID)
+
+	var histories []ArticleHistory
+	if err := supabaseSelect(path, &histories); err != nil {
+		return nil, err
+	}
+	if len(histories) == 0 {
+		return nil, nil
+	}
+	return &histories[0], nil
+}
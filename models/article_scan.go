@@ -0,0 +1,131 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ArticleQuery 記事を絞り込む条件（未指定の項目では絞り込まない）
+type ArticleQuery struct {
+	Delivered *bool      // true: 配信済み、false: 未配信
+	Field     string     // 一致した分野に含まれる分野名
+	Keyword   string     // 本文（タイトル・URL・要約）の部分一致
+	Since     *time.Time // 予約した時刻がこの時刻以降
+	Until     *time.Time // 予約した時刻がこの時刻より前
+}
+
+// ScanArticles 条件に一致するルームの記事を記事IDの順にpageSize件ずつ取得してfnに渡す
+// 全件を一度に読み込まないため、記事の多いルームでも使える
+// reserve_articleから配信・削除された記事も含めるため、続けてarticle_historyの行を同じ条件で渡す
+// （未配信の記事だけを指定した場合と、reserve_articleで渡した記事は除く）
+func ScanArticles(roomID string, q ArticleQuery, pageSize int, fn func([]Article) error) error {
+	filter, err := articleQueryFilter(roomID, q, "created_at")
+	if err != nil {
+		return err
+	}
+
+	scanned := make(map[int64]bool)
+	var lastID int64
+	for {
+		path := fmt.Sprintf("reserve_article?select=article_id,room_id,content,score,matched_fields,created_at,delivered_at%s&article_id=gt.%d&order=article_id.asc&limit=%d",
+			filter, lastID, pageSize)
+
+		var articles []Article
+		if err := supabaseSelect(path, &articles); err != nil {
+			return err
+		}
+		if len(articles) > 0 {
+			for _, article := range articles {
+				scanned[article.ArticleID] = true
+			}
+			if err := fn(articles); err != nil {
+				return err
+			}
+		}
+		if len(articles) < pageSize {
+			break
+		}
+		lastID = articles[len(articles)-1].ArticleID
+	}
+
+	if q.Delivered != nil && !*q.Delivered {
+		return nil
+	}
+	return scanArticleHistory(roomID, q, pageSize, scanned, fn)
+}
+
+// scanArticleHistory 条件に一致する履歴の行を記事に変換してpageSize件ずつfnに渡す（scannedの記事は除く）
+func scanArticleHistory(roomID string, q ArticleQuery, pageSize int, scanned map[int64]bool, fn func([]Article) error) error {
+	filter, err := articleQueryFilter(roomID, q, "reserved_at")
+	if err != nil {
+		return err
+	}
+
+	var lastID int64
+	for {
+		path := fmt.Sprintf("article_history?select=article_id,room_id,content,score,matched_fields,reserved_at,delivered_at,deleted_at%s&article_id=gt.%d&order=article_id.asc&limit=%d",
+			filter, lastID, pageSize)
+
+		var histories []ArticleHistory
+		if err := supabaseSelect(path, &histories); err != nil {
+			return err
+		}
+		var articles []Article
+		for _, h := range histories {
+			if scanned[h.ArticleID] {
+				continue
+			}
+			articles = append(articles, Article{
+				ArticleID:     h.ArticleID,
+				RoomID:        h.RoomID,
+				Content:       h.Content,
+				Score:         h.Score,
+				MatchedFields: h.MatchedFields,
+				CreatedAt:     h.ReservedAt,
+				DeliveredAt:   h.DeliveredAt,
+				DeletedAt:     h.DeletedAt,
+			})
+		}
+		if len(articles) > 0 {
+			if err := fn(articles); err != nil {
+				return err
+			}
+		}
+		if len(histories) < pageSize {
+			return nil
+		}
+		lastID = histories[len(histories)-1].ArticleID
+	}
+}
+
+// articleQueryFilter 絞り込み条件をクエリ文字列にする（createdAtは予約した時刻の列名）
+func articleQueryFilter(roomID string, q ArticleQuery, createdAt string) (string, error) {
+	filter := "&room_id=eq." + url.QueryEscape(roomID)
+	if q.Delivered != nil {
+		if *q.Delivered {
+			filter += "&delivered_at=not.is.null"
+		} else {
+			filter += "&delivered_at=is.null"
+		}
+	}
+	if q.Field != "" {
+		field, err := json.Marshal([]string{q.Field})
+		if err != nil {
+			return "", err
+		}
+		filter += "&matched_fields=cs." + url.QueryEscape(string(field))
+	}
+	if keyword := strings.TrimSpace(q.Keyword); keyword != "" {
+		filter += "&content=ilike." + url.QueryEscape(likePattern(keyword))
+	}
+	if q.Since != nil {
+		filter += "&" + createdAt + "=gte." + url.QueryEscape(q.Since.UTC().Format(time.RFC3339))
+	}
+	if q.Until != nil {
+		filter += "&" + createdAt + "=lt." + url.QueryEscape(q.Until.UTC().Format(time.RFC3339))
+	}
+	return filter, nil
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"net/url"
+	"time"
+)
+
+// ArticleState 記事ごとの既読・スター・メモの状態
+// ログインはルーム単位のため、状態もルームと記事の組み合わせで管理する
+type ArticleState struct {
+	RoomID    string     `json:"room_id"`
+	ArticleID int64      `json:"article_id"`
+	IsRead    bool       `json:"is_read"`
+	IsStarred bool       `json:"is_starred"`
+	Note      string     `json:"note"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// FetchArticleStates ルームの記事状態を記事IDをキーにして取得
+func FetchArticleStates(roomID string) (map[int64]ArticleState, error) {
+	path := "article_state?select=*&room_id=eq." + url.QueryEscape(roomID)
+
+	var states []ArticleState
+	if err := supabaseSelect(path, &states); err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64]ArticleState, len(states))
+	for _, state := range states {
+		result[state.ArticleID] = state
+	}
+	return result, nil
+}
+
+// UpdateArticleState 記事状態のうち指定された項目だけを登録・更新する
+// changesには "is_read"、"is_starred"、"note" のうち変更する項目を指定する
+func UpdateArticleState(roomID string, articleID int64, changes map[string]interface{}) error {
+	row := map[string]interface{}{
+		"room_id":    roomID,
+		"article_id": articleID,
+		"updated_at": time.Now().UTC(),
+	}
+	for k, v := range changes {
+		row[k] = v
+	}
+
+	_, err := supabaseRequest("POST", "article_state?on_conflict=room_id,article_id", row,
+		"resolution=merge-duplicates,return=minimal")
+	return err
+}
@@ -0,0 +1,248 @@
+package models
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// 記事URLの最大長
+const maxArticleURLLength = 2048
+
+// trackingParams 記事の内容に関係しない計測用のクエリパラメータ
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "yclid": true, "msclkid": true, "twclid": true,
+	"igshid": true, "mc_cid": true, "mc_eid": true, "_ga": true, "_gl": true, "_hsenc": true,
+	"_hsmi": true, "mkt_tok": true, "ref_src": true, "ref_url": true, "spm": true, "cmpid": true,
+	"n_cid": true,
+}
+
+// trackingParamPrefixes 前方一致で除去する計測用のクエリパラメータ
+var trackingParamPrefixes = []string{"utm_", "pk_", "mtm_", "hsa_"}
+
+// redirectorParams URLをクエリで受け取って転送するだけのリダイレクタ（ホストとパス → 転送先のパラメータ）
+var redirectorParams = map[string]string{
+	"www.google.com/url":              "q",
+	"google.com/url":                  "q",
+	"l.facebook.com/l.php":            "u",
+	"lm.facebook.com/l.php":           "u",
+	"out.reddit.com/":                 "url",
+	"www.youtube.com/redirect":        "q",
+	"slack-redir.net/link":            "url",
+	"href.li/":                        "url",
+	"getpocket.com/redirect":          "url",
+	"t.umblr.com/redirect":            "z",
+	"www.linkedin.com/redir/redirect": "url",
+}
+
+// CanonicalizeArticleURL 記事URLを保存用の正規の形にする
+// 既知のリダイレクタとAMP版を元の記事URLに戻し、計測用のクエリとフラグメントを除き、
+// スキームとホストを小文字にして既定ポートを除く
+func CanonicalizeArticleURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", errors.New("URLが指定されていません")
+	}
+	if len(raw) > maxArticleURLLength {
+		return "", errors.New("URLが長すぎます")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", errors.New("URLの形式が正しくありません")
+	}
+
+	// リダイレクタやAMPキャッシュは何重にも包まれていることがあるので、変わらなくなるまで戻す
+	for i := 0; i < 5; i++ {
+		if err := normalizeURLHost(u); err != nil {
+			return "", err
+		}
+		unwrapped := unwrapRedirector(u)
+		if unwrapped == nil {
+			unwrapped = unwrapAMPCache(u)
+		}
+		if unwrapped == nil {
+			break
+		}
+		u = unwrapped
+	}
+
+	stripAMP(u)
+	stripTrackingParams(u)
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	// "[" "]" や空白はChatwork記法の区切りと誤認されるため受け付けない（転送先のURLも同様）
+	if strings.ContainsAny(u.RequestURI(), "[]") || strings.IndexFunc(u.RequestURI(), unicode.IsSpace) >= 0 {
+		return "", errors.New("URLに使えない文字（[ ] や空白）が含まれています")
+	}
+
+	return u.String(), nil
+}
+
+// NormalizeArticleURL 重複判定用に記事URLを正規化する
+// CanonicalizeArticleURLに加えて、httpとhttps・www.の有無・末尾スラッシュの違いを同一視し、クエリを並べ替える
+func NormalizeArticleURL(raw string) (string, error) {
+	canonical, err := CanonicalizeArticleURL(raw)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(canonical)
+	if err != nil {
+		return "", errors.New("URLの形式が正しくありません")
+	}
+
+	u.Scheme = "https"
+	u.Host = strings.TrimPrefix(u.Host, "www.")
+	if u.Path != "/" {
+		u.Path = strings.TrimSuffix(u.Path, "/")
+		u.RawPath = ""
+	}
+	// Encodeはキーの順に並べ替える
+	u.RawQuery = u.Query().Encode()
+
+	return u.String(), nil
+}
+
+// normalizeURLHost スキームとホストを検証して小文字にし、既定ポートと末尾のドットを除く
+func normalizeURLHost(u *url.URL) error {
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("URLはhttpまたはhttpsで指定してください")
+	}
+	if u.Hostname() == "" || u.User != nil {
+		return errors.New("URLの形式が正しくありません")
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+	return nil
+}
+
+// unwrapRedirector 既知のリダイレクタであれば転送先のURLを返す（該当しない場合はnil）
+func unwrapRedirector(u *url.URL) *url.URL {
+	param, ok := redirectorParams[u.Host+u.Path]
+	if !ok {
+		param, ok = redirectorParams[u.Host+"/"]
+	}
+	if !ok {
+		return nil
+	}
+
+	target := u.Query().Get(param)
+	if target == "" {
+		return nil
+	}
+	next, err := url.Parse(target)
+	if err != nil || (next.Scheme != "http" && next.Scheme != "https") {
+		return nil
+	}
+	return next
+}
+
+// unwrapAMPCache GoogleのAMPビューアーやAMPキャッシュのURLであれば元のURLを返す（該当しない場合はnil）
+// 例: https://www.google.com/amp/s/example.com/a、https://example-com.cdn.ampproject.org/c/s/example.com/a
+func unwrapAMPCache(u *url.URL) *url.URL {
+	var rest string
+	switch {
+	case (u.Host == "www.google.com" || u.Host == "google.com") && strings.HasPrefix(u.Path, "/amp/"):
+		rest = strings.TrimPrefix(u.Path, "/amp/")
+	case strings.HasSuffix(u.Host, ".cdn.ampproject.org"):
+		rest = strings.TrimPrefix(u.Path, "/")
+		// /c/（HTML）、/v/（ビューアー）などの種類を表す部分を除く
+		if i := strings.Index(rest, "/"); i == 1 {
+			rest = rest[i+1:]
+		}
+	default:
+		return nil
+	}
+
+	scheme := "http"
+	if strings.HasPrefix(rest, "s/") {
+		scheme = "https"
+		rest = strings.TrimPrefix(rest, "s/")
+	}
+	if rest == "" {
+		return nil
+	}
+
+	next, err := url.Parse(scheme + "://" + rest)
+	if err != nil || next.Hostname() == "" {
+		return nil
+	}
+	next.RawQuery = u.RawQuery
+	return next
+}
+
+// stripAMP AMP版を示すホスト名・パス・クエリを通常版の形に戻す
+func stripAMP(u *url.URL) {
+	// amp.dev のようにホスト名そのものの場合は除かない
+	if rest := strings.TrimPrefix(u.Host, "amp."); rest != u.Host && strings.Contains(rest, ".") {
+		u.Host = rest
+	}
+
+	path := u.Path
+	switch {
+	case strings.HasSuffix(u.Path, "/amp") || strings.HasSuffix(u.Path, "/amp/"):
+		path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), "/amp") + "/"
+	case strings.HasSuffix(u.Path, ".amp"):
+		path = strings.TrimSuffix(u.Path, ".amp")
+	case strings.HasPrefix(u.Path, "/amp/"):
+		path = strings.TrimPrefix(u.Path, "/amp")
+	}
+	if path != u.Path {
+		u.Path = path
+		u.RawPath = ""
+	}
+
+	removeQueryParams(u, func(key string, values []string) bool {
+		return key == "amp" || (key == "outputType" && len(values) > 0 && strings.EqualFold(values[0], "amp"))
+	})
+}
+
+// stripTrackingParams 計測用のクエリパラメータを除く
+func stripTrackingParams(u *url.URL) {
+	removeQueryParams(u, func(key string, _ []string) bool {
+		lower := strings.ToLower(key)
+		if trackingParams[lower] {
+			return true
+		}
+		for _, prefix := range trackingParamPrefixes {
+			if strings.HasPrefix(lower, prefix) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// removeQueryParams removeに該当するクエリパラメータを除く
+// 除くものがない場合は元のクエリ文字列をそのまま残す
+func removeQueryParams(u *url.URL, remove func(key string, values []string) bool) {
+	q := u.Query()
+	changed := false
+	for key, values := range q {
+		if remove(key, values) {
+			q.Del(key)
+			changed = true
+		}
+	}
+	if changed {
+		u.RawQuery = q.Encode()
+	}
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// クリックされたリンクの掲載先
+const (
+	ClickSourceChatwork = "chatwork" // 配信したメッセージ
+	ClickSourceWeb      = "web"      // 記事一覧ページ
+	ClickSourceFeed     = "feed"     // 購読フィード
+)
+
+// ArticleClick 記事のリンクのクリック
+type ArticleClick struct {
+	ID        int64     `json:"id,omitempty"`
+	RoomID    string    `json:"room_id"`
+	ArticleID int64     `json:"article_id"`
+	URL       string    `json:"url"`
+	Source    string    `json:"source"`
+	UserAgent string    `json:"user_agent"` // ブラウザ・OSの種類だけにしたUser-Agent
+	ClickedAt time.Time `json:"clicked_at"`
+}
+
+// RecordArticleClick クリックを記録する
+func RecordArticleClick(click ArticleClick) error {
+	_, err := supabaseRequest("POST", "article_click", click, "return=minimal")
+	return err
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// 配信結果
+const (
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// DeliveryLog 記事をChatworkへ配信した1回分の記録
+type DeliveryLog struct {
+	ID          int64     `json:"id,omitempty"`
+	RoomID      string    `json:"room_id"`
+	ArticleID   int64     `json:"article_id"`
+	Status      string    `json:"status"`
+	MessageID   string    `json:"message_id"`
+	Error       string    `json:"error"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+// RecordDelivery 配信の記録を保存
+func RecordDelivery(log DeliveryLog) error {
+	_, err := supabaseRequest("POST", "delivery_log", log, "return=minimal")
+	return err
+}
+
+// MarkArticleDelivered 記事を配信済みにし、配信順から外す
+func MarkArticleDelivered(articleID int64, deliveredAt time.Time) error {
+	return UpdateArticle(articleID, map[string]interface{}{
+		"delivered_at":        deliveredAt,
+		"next_retry_at":       nil,
+		"last_delivery_error": "",
+		"queue_position":      nil,
+		"pinned":              false,
+	})
+}
+
+// MarkArticleDeliveryFailed 配信の失敗回数と次回の再試行時刻を記録する
+// nextRetryAtがnilの場合は再試行しない
+func MarkArticleDeliveryFailed(articleID int64, attempts int, nextRetryAt *time.Time, message string) error {
+	return UpdateArticle(articleID, map[string]interface{}{
+		"delivery_attempts":   attempts,
+		"next_retry_at":       nextRetryAt,
+		"last_delivery_error": message,
+	})
+}
+
+// FetchRetryDueArticles 再試行時刻を過ぎた配信失敗の記事を取得する（試行回数がmaxAttempts以上の記事は除く）
+func FetchRetryDueArticles(roomID string, maxAttempts int, now time.Time) ([]Article, error) {
+	path := fmt.Sprintf("reserve_article?select=*&room_id=eq.%s&delivered_at=is.null&delivery_attempts=gt.0&delivery_attempts=lt.%d&next_retry_at=lte.%s&order=next_retry_at.asc",
+		url.QueryEscape(roomID), maxAttempts, url.QueryEscape(now.UTC().Format(time.RFC3339)))
+
+	var articles []Article
+	if err := supabaseSelect(path, &articles); err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
+// FetchDeliverySlot ルームで最後に配信した枠の時刻（未記録の場合はnil）
+func FetchDeliverySlot(roomID string) (*time.Time, error) {
+	var rows []struct {
+		SlotAt time.Time `json:"slot_at"`
+	}
+	if err := supabaseSelect("delivery_slot?select=slot_at&room_id=eq."+url.QueryEscape(roomID), &rows); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &rows[0].SlotAt, nil
+}
+
+// ClaimDeliverySlot ルームの配信枠を確保する（記録済みの枠より新しい場合だけ記録してtrueを返す）
+// 条件付きの更新と重複を無視する登録で行うため、複数のインスタンスが同じ枠を確保しても1つだけが成功する
+func ClaimDeliverySlot(roomID string, slot time.Time) (bool, error) {
+	row := map[string]interface{}{
+		"room_id":    roomID,
+		"slot_at":    slot.UTC(),
+		"updated_at": time.Now().UTC(),
+	}
+
+	path := fmt.Sprintf("delivery_slot?room_id=eq.%s&slot_at=lt.%s",
+		url.QueryEscape(roomID), url.QueryEscape(slot.UTC().Format(time.RFC3339)))
+	body, err := supabaseRequest("PATCH", path, row, "return=representation")
+	if err != nil {
+		return false, err
+	}
+	if claimed, err := returnedRows(body); err != nil || claimed {
+		return claimed, err
+	}
+
+	// 未記録のルームは登録する（同時に登録された場合は確保できなかったものとする）
+	body, err = supabaseRequest("POST", "delivery_slot?on_conflict=room_id", row, "resolution=ignore-duplicates,return=representation")
+	if err != nil {
+		return false, err
+	}
+	return returnedRows(body)
+}
+
+// returnedRows return=representationのレスポンスに行が含まれるかどうか
+func returnedRows(body []byte) (bool, error) {
+	var rows []json.RawMessage
+	if err := json.Unmarshal(body, &rows); err != nil {
+		return false, fmt.Errorf("JSONのパースに失敗しました: %w", err)
+	}
+	return len(rows) > 0, nil
+}
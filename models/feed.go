@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Feed ルームが購読するRSS/Atomフィード
+type Feed struct {
+	ID            int64      `json:"id,omitempty"`
+	RoomID        string     `json:"room_id"`
+	URL           string     `json:"url"`
+	Title         string     `json:"title"`
+	ETag          string     `json:"etag"`
+	LastModified  string     `json:"last_modified"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+	LastError     string     `json:"last_error"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+}
+
+// FetchFeeds ルームのフィード一覧を取得
+func FetchFeeds(roomID string) ([]Feed, error) {
+	var feeds []Feed
+	if err := supabaseSelect("feed?select=*&order=id.asc&room_id=eq."+url.QueryEscape(roomID), &feeds); err != nil {
+		return nil, err
+	}
+	return feeds, nil
+}
+
+// FetchAllFeeds すべてのルームのフィードを取得
+func FetchAllFeeds() ([]Feed, error) {
+	var feeds []Feed
+	if err := supabaseSelect("feed?select=*&order=id.asc", &feeds); err != nil {
+		return nil, err
+	}
+	return feeds, nil
+}
+
+// CreateFeed フィードを登録し、採番されたフィードを返す
+func CreateFeed(feed Feed) (*Feed, error) {
+	body, err := supabaseRequest("POST", "feed", map[string]interface{}{
+		"room_id": feed.RoomID,
+		"url":     feed.URL,
+		"title":   feed.Title,
+	}, "return=representation")
+	if err != nil {
+		return nil, err
+	}
+
+	var created []Feed
+	if err := json.Unmarshal(body, &created); err != nil {
+		return nil, fmt.Errorf("JSONのパースに失敗しました: %w", err)
+	}
+	if len(created) == 0 {
+		return nil, fmt.Errorf("登録したフィードが返されませんでした")
+	}
+	return &created[0], nil
+}
+
+// DeleteFeed ルームのフィードを削除（見つからなかった場合はfalse）
+func DeleteFeed(roomID string, id int64) (bool, error) {
+	path := fmt.Sprintf("feed?id=eq.%d&room_id=eq.%s", id, url.QueryEscape(roomID))
+	body, err := supabaseRequest("DELETE", path, nil, "return=representation")
+	if err != nil {
+		return false, err
+	}
+	var deleted []Feed
+	if err := json.Unmarshal(body, &deleted); err != nil {
+		return false, fmt.Errorf("JSONのパースに失敗しました: %w", err)
+	}
+	return len(deleted) > 0, nil
+}
+
+// UpdateFeedFetchState 取得結果（条件付きGET用のETag・Last-Modifiedとエラー）を保存
+func UpdateFeedFetchState(feed Feed) error {
+	path := fmt.Sprintf("feed?id=eq.%d", feed.ID)
+	_, err := supabaseRequest("PATCH", path, map[string]interface{}{
+		"title":           feed.Title,
+		"etag":            feed.ETag,
+		"last_modified":   feed.LastModified,
+		"last_fetched_at": feed.LastFetchedAt,
+		"last_error":      feed.LastError,
+	}, "return=minimal")
+	return err
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// 記事への反応の種類
+const (
+	FeedbackUp    = "up"    // 👍
+	FeedbackDown  = "down"  // 👎
+	FeedbackClear = "clear" // 👍・👎の取り消し
+	FeedbackClick = "click" // 記事のURLを開いた
+)
+
+// ValidFeedbackSignal 記事への反応の種類として正しいかどうか
+func ValidFeedbackSignal(signal string) bool {
+	switch signal {
+	case FeedbackUp, FeedbackDown, FeedbackClear, FeedbackClick:
+		return true
+	}
+	return false
+}
+
+// ArticleFeedback 記事への反応
+// 記事が配信・削除されても分野ごとに集計できるよう、反応した時点で一致していた分野を記録する
+type ArticleFeedback struct {
+	ID            int64      `json:"id,omitempty"`
+	RoomID        string     `json:"room_id"`
+	ArticleID     int64      `json:"article_id"`
+	Signal        string     `json:"signal"`
+	MatchedFields []string   `json:"matched_fields"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+}
+
+// RecordArticleFeedback 記事への反応を記録する
+func RecordArticleFeedback(article Article, signal string) error {
+	matched := article.MatchedFields
+	if matched == nil {
+		matched = []string{}
+	}
+	_, err := supabaseRequest("POST", "article_feedback", map[string]interface{}{
+		"room_id":        article.RoomID,
+		"article_id":     article.ArticleID,
+		"signal":         signal,
+		"matched_fields": matched,
+	}, "return=minimal")
+	return err
+}
+
+// FetchArticleFeedback ルームのsince以降の反応を古い順に取得
+func FetchArticleFeedback(roomID string, since time.Time) ([]ArticleFeedback, error) {
+	path := fmt.Sprintf("article_feedback?select=*&room_id=eq.%s&created_at=gte.%s&order=created_at.asc,id.asc",
+		url.QueryEscape(roomID), url.QueryEscape(since.UTC().Format(time.RFC3339)))
+
+	var feedback []ArticleFeedback
+	if err := supabaseSelect(path, &feedback); err != nil {
+		return nil, err
+	}
+	return feedback, nil
+}
+
+// FetchArticleVotes ルームの記事ごとの現在の👍・👎を取得（取り消した記事と未評価の記事は含まない）
+func FetchArticleVotes(roomID string) (map[int64]string, error) {
+	path := fmt.Sprintf("article_feedback?select=article_id,signal&room_id=eq.%s&signal=in.%s&order=created_at.asc,id.asc",
+		url.QueryEscape(roomID), inFilter([]string{FeedbackUp, FeedbackDown, FeedbackClear}))
+
+	var rows []ArticleFeedback
+	if err := supabaseSelect(path, &rows); err != nil {
+		return nil, err
+	}
+
+	votes := make(map[int64]string)
+	for _, row := range rows {
+		if row.Signal == FeedbackClear {
+			delete(votes, row.ArticleID)
+			continue
+		}
+		votes[row.ArticleID] = row.Signal
+	}
+	return votes, nil
+}
+
+// ArticleMatch 記事と一致した分野の組み合わせ（反応の集計で記事の件数を数えるのに使う）
+type ArticleMatch struct {
+	ArticleID     int64    `json:"article_id"`
+	MatchedFields []string `json:"matched_fields"`
+}
+
+// FetchHistoryMatches ルームのsince以降に配信・削除された記事の一致した分野を取得
+func FetchHistoryMatches(roomID string, since time.Time) ([]ArticleMatch, error) {
+	path := fmt.Sprintf("article_history?select=article_id,matched_fields&room_id=eq.%s&recorded_at=gte.%s",
+		url.QueryEscape(roomID), url.QueryEscape(since.UTC().Format(time.RFC3339)))
+
+	var matches []ArticleMatch
+	if err := supabaseSelect(path, &matches); err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
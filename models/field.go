@@ -0,0 +1,94 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// Field 分野（登録ワード）モデル
+type Field struct {
+	RoomID    string `json:"room_id"`
+	FieldName string `json:"field_name"`
+	Priority  int    `json:"priority"`
+	SortOrder *int   `json:"sort_order"` // 並べ替えていない分野はnil（nullのまま保存する）
+}
+
+// 興味の強さの範囲
+const (
+	MinPriority     = 1
+	MaxPriority     = 5
+	DefaultPriority = 3
+)
+
+// ValidPriority 優先度が1〜5の範囲内かどうか
+func ValidPriority(priority int) bool {
+	return priority >= MinPriority && priority <= MaxPriority
+}
+
+// FetchFields ルームIDに紐づく分野を並び順で取得
+func FetchFields(roomID string) ([]Field, error) {
+	path := fmt.Sprintf("field?select=room_id,field_name,priority,sort_order&room_id=eq.%s&order=sort_order.asc.nullslast,field_name.asc",
+		url.QueryEscape(roomID))
+
+	var fields []Field
+	if err := supabaseSelect(path, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// UpsertFields 複数の分野をまとめて登録・更新する
+// PostgRESTの一括upsertは1つのSQL文として実行されるため、全件が反映されるか全件失敗するかのどちらかになる
+func UpsertFields(fields []Field) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	_, err := supabaseRequest("POST", "field?on_conflict=room_id,field_name", fields,
+		"resolution=merge-duplicates,return=minimal")
+	return err
+}
+
+// UpdateFieldPriority 分野の優先度だけを更新する（削除された分野は作り直さない）
+func UpdateFieldPriority(roomID, fieldName string, priority int) error {
+	return UpdateField(roomID, fieldName, map[string]interface{}{"priority": priority})
+}
+
+// UpdateField 分野の指定した列を更新する（削除された分野は作り直さない）
+func UpdateField(roomID, fieldName string, changes map[string]interface{}) error {
+	path := fmt.Sprintf("field?room_id=eq.%s&field_name=eq.%s", url.QueryEscape(roomID), url.QueryEscape(fieldName))
+	_, err := supabaseRequest("PATCH", path, changes, "return=minimal")
+	return err
+}
+
+// NormalizeFieldName 分野名を正規化する（admin.htmlの入力時の正規化と同じ規則）
+// 全角スペースと連続する空白を1つの半角スペースにまとめ、全角英数字を半角に変換し、
+// 先頭が英字の場合は先頭を大文字・残りを小文字にする
+func NormalizeFieldName(name string) string {
+	name = strings.Join(strings.Fields(strings.ReplaceAll(name, "　", " ")), " ")
+
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'Ａ' && r <= 'Ｚ', r >= 'ａ' && r <= 'ｚ', r >= '０' && r <= '９':
+			return r - 'Ａ' + 'A'
+		}
+		return r
+	}, name)
+
+	runes := []rune(name)
+	if len(runes) > 0 && runes[0] < unicode.MaxASCII && unicode.IsLetter(runes[0]) {
+		return strings.ToUpper(string(runes[0])) + strings.ToLower(string(runes[1:]))
+	}
+	return name
+}
+
+// DeleteFieldsExcept 指定した分野名以外の分野をすべて削除する
+func DeleteFieldsExcept(roomID string, keep []string) error {
+	path := "field?room_id=eq." + url.QueryEscape(roomID)
+	if len(keep) > 0 {
+		path += "&field_name=not.in." + inFilter(keep)
+	}
+	_, err := supabaseRequest("DELETE", path, nil, "return=minimal")
+	return err
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// TemplateField テンプレートに含まれる分野
+type TemplateField struct {
+	FieldName string `json:"field_name"`
+	Priority  int    `json:"priority"`
+}
+
+// FieldTemplate 複数のルームに適用できる分野のテンプレート（例:「バックエンドチーム」）
+type FieldTemplate struct {
+	ID              int64           `json:"id,omitempty"`
+	Name            string          `json:"name"`
+	CreatedByRoomID string          `json:"created_by_room_id"`
+	Fields          []TemplateField `json:"fields"`
+	CreatedAt       *time.Time      `json:"created_at,omitempty"`
+}
+
+// FetchFieldTemplates テンプレート一覧を名前順で取得
+func FetchFieldTemplates() ([]FieldTemplate, error) {
+	var templates []FieldTemplate
+	if err := supabaseSelect("field_template?select=*&order=name.asc", &templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// FetchFieldTemplate IDを指定してテンプレートを取得（存在しない場合はnil）
+func FetchFieldTemplate(id int64) (*FieldTemplate, error) {
+	var templates []FieldTemplate
+	if err := supabaseSelect(fmt.Sprintf("field_template?select=*&id=eq.%d", id), &templates); err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		return nil, nil
+	}
+	return &templates[0], nil
+}
+
+// CreateFieldTemplate テンプレートを登録
+func CreateFieldTemplate(template FieldTemplate) error {
+	_, err := supabaseRequest("POST", "field_template", template, "return=minimal")
+	return err
+}
+
+// DeleteFieldTemplate テンプレートを削除（作成したルームのみ削除可能）
+// 削除対象が見つからなかった場合はfalseを返す
+func DeleteFieldTemplate(id int64, roomID string) (bool, error) {
+	path := fmt.Sprintf("field_template?id=eq.%d&created_by_room_id=eq.%s", id, url.QueryEscape(roomID))
+	body, err := supabaseRequest("DELETE", path, nil, "return=representation")
+	if err != nil {
+		return false, err
+	}
+	var deleted []FieldTemplate
+	if err := json.Unmarshal(body, &deleted); err != nil {
+		return false, fmt.Errorf("JSONのパースに失敗しました: %w", err)
+	}
+	return len(deleted) > 0, nil
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// 優先度の自動調整のモード
+const (
+	TuningModeOff     = "off"     // 調整しない
+	TuningModePropose = "propose" // 変更案を表示する
+	TuningModeApply   = "apply"   // 変更案を定期的に自動で反映する
+)
+
+// ValidTuningMode 自動調整のモードとして正しいかどうか
+func ValidTuningMode(mode string) bool {
+	switch mode {
+	case TuningModeOff, TuningModePropose, TuningModeApply:
+		return true
+	}
+	return false
+}
+
+// FieldTuning ルームごとの優先度の自動調整の設定
+type FieldTuning struct {
+	RoomID    string     `json:"room_id"`
+	Mode      string     `json:"mode"`
+	LastRunAt *time.Time `json:"last_run_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// PriorityChange 反応をもとに変更した分野の優先度の記録
+type PriorityChange struct {
+	ID          int64      `json:"id,omitempty"`
+	RoomID      string     `json:"room_id"`
+	FieldName   string     `json:"field_name"`
+	OldPriority int        `json:"old_priority"`
+	NewPriority int        `json:"new_priority"`
+	Reason      string     `json:"reason"`
+	Automatic   bool       `json:"automatic"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+// FetchFieldTuning ルームの自動調整の設定を取得（未設定の場合はモードoffの設定を返す）
+func FetchFieldTuning(roomID string) (*FieldTuning, error) {
+	var tunings []FieldTuning
+	if err := supabaseSelect("field_tuning?select=*&room_id=eq."+url.QueryEscape(roomID), &tunings); err != nil {
+		return nil, err
+	}
+	if len(tunings) == 0 {
+		return &FieldTuning{RoomID: roomID, Mode: TuningModeOff}, nil
+	}
+	return &tunings[0], nil
+}
+
+// FetchFieldTuningsByMode 指定したモードのルームの設定を取得
+func FetchFieldTuningsByMode(mode string) ([]FieldTuning, error) {
+	var tunings []FieldTuning
+	if err := supabaseSelect("field_tuning?select=*&mode=eq."+url.QueryEscape(mode), &tunings); err != nil {
+		return nil, err
+	}
+	return tunings, nil
+}
+
+// SaveFieldTuningMode ルームの自動調整のモードを登録・更新
+func SaveFieldTuningMode(roomID, mode string) error {
+	_, err := supabaseRequest("POST", "field_tuning?on_conflict=room_id", map[string]interface{}{
+		"room_id":    roomID,
+		"mode":       mode,
+		"updated_at": time.Now().UTC(),
+	}, "resolution=merge-duplicates,return=minimal")
+	return err
+}
+
+// MarkFieldTuningRun ルームの自動調整を実行した時刻を記録
+func MarkFieldTuningRun(roomID string, at time.Time) error {
+	_, err := supabaseRequest("PATCH", "field_tuning?room_id=eq."+url.QueryEscape(roomID), map[string]interface{}{
+		"last_run_at": at,
+	}, "return=minimal")
+	return err
+}
+
+// RecordPriorityChanges 優先度の変更をまとめて記録
+func RecordPriorityChanges(changes []PriorityChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	_, err := supabaseRequest("POST", "field_priority_change", changes, "return=minimal")
+	return err
+}
+
+// FetchPriorityChanges ルームの優先度の変更を新しい順にlimit件取得
+func FetchPriorityChanges(roomID string, limit int) ([]PriorityChange, error) {
+	path := fmt.Sprintf("field_priority_change?select=*&room_id=eq.%s&order=created_at.desc,id.desc&limit=%d",
+		url.QueryEscape(roomID), limit)
+
+	var changes []PriorityChange
+	if err := supabaseSelect(path, &changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"net/url"
+	"time"
+)
+
+// PageMetadata 記事URLのページから取得したメタデータ
+// urlは正規化済みのURLで、取得に失敗した場合もErrorを記録して再取得を控える
+type PageMetadata struct {
+	URL          string     `json:"url"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	Image        string     `json:"image"`
+	CanonicalURL string     `json:"canonical_url"`
+	PublishedAt  *time.Time `json:"published_at"`
+	Error        string     `json:"error"`
+	FetchedAt    time.Time  `json:"fetched_at"`
+}
+
+// FetchPageMetadata キャッシュ済みのメタデータを取得（存在しない場合はnil）
+func FetchPageMetadata(normalizedURL string) (*PageMetadata, error) {
+	var rows []PageMetadata
+	if err := supabaseSelect("page_metadata?select=*&url=eq."+url.QueryEscape(normalizedURL), &rows); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &rows[0], nil
+}
+
+// SavePageMetadata メタデータをキャッシュに保存（同じURLの場合は上書き）
+func SavePageMetadata(meta PageMetadata) error {
+	_, err := supabaseRequest("POST", "page_metadata?on_conflict=url", meta,
+		"resolution=merge-duplicates,return=minimal")
+	return err
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"net/url"
+	"time"
+)
+
+// RetentionRule ルームごとの予約記事の保持ルール
+// MaxAgeDays・MaxCountが0の場合は制限しない
+type RetentionRule struct {
+	RoomID              string     `json:"room_id"`
+	MaxAgeDays          int        `json:"max_age_days"`          // 予約してからこの日数を過ぎた記事を削除する
+	MaxCount            int        `json:"max_count"`             // 記事がこの件数を超えた分を削除する
+	DeleteAfterDelivery bool       `json:"delete_after_delivery"` // 配信した記事を削除する
+	DeliveredKeepDays   int        `json:"delivered_keep_days"`   // 配信した記事を削除するまでの日数
+	LastPrunedAt        *time.Time `json:"last_pruned_at"`
+	UpdatedAt           *time.Time `json:"updated_at,omitempty"`
+}
+
+// Active いずれかのルールが設定されているかどうか
+func (r RetentionRule) Active() bool {
+	return r.MaxAgeDays > 0 || r.MaxCount > 0 || r.DeleteAfterDelivery
+}
+
+// FetchRetentionRule ルームの保持ルールを取得（未設定の場合は制限なしのルールを返す）
+func FetchRetentionRule(roomID string) (*RetentionRule, error) {
+	var rules []RetentionRule
+	if err := supabaseSelect("retention_rule?select=*&room_id=eq."+url.QueryEscape(roomID), &rules); err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return &RetentionRule{RoomID: roomID}, nil
+	}
+	return &rules[0], nil
+}
+
+// FetchRetentionRules 保持ルールを設定しているすべてのルームのルールを取得
+func FetchRetentionRules() ([]RetentionRule, error) {
+	var rules []RetentionRule
+	if err := supabaseSelect("retention_rule?select=*", &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// SaveRetentionRule ルームの保持ルールを登録・更新
+func SaveRetentionRule(rule RetentionRule) error {
+	_, err := supabaseRequest("POST", "retention_rule?on_conflict=room_id", map[string]interface{}{
+		"room_id":               rule.RoomID,
+		"max_age_days":          rule.MaxAgeDays,
+		"max_count":             rule.MaxCount,
+		"delete_after_delivery": rule.DeleteAfterDelivery,
+		"delivered_keep_days":   rule.DeliveredKeepDays,
+		"updated_at":            time.Now().UTC(),
+	}, "resolution=merge-duplicates,return=minimal")
+	return err
+}
+
+// MarkRetentionPruned 保持ルールを適用した時刻を記録
+func MarkRetentionPruned(roomID string, at time.Time) error {
+	_, err := supabaseRequest("PATCH", "retention_rule?room_id=eq."+url.QueryEscape(roomID), map[string]interface{}{
+		"last_pruned_at": at,
+	}, "return=minimal")
+	return err
+}
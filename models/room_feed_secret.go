@@ -0,0 +1,39 @@
+package models
+
+import (
+	"net/url"
+	"time"
+)
+
+// RoomFeedSecret ルームの購読フィードのURLに含める秘密の値
+// 値そのものは発行時にだけ返し、保存するのはハッシュだけにする
+type RoomFeedSecret struct {
+	RoomID     string    `json:"room_id"`
+	SecretHash string    `json:"secret_hash"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// FetchRoomFeedSecret ルームの購読フィードの秘密の値を取得（発行していない場合はnil）
+func FetchRoomFeedSecret(roomID string) (*RoomFeedSecret, error) {
+	var secrets []RoomFeedSecret
+	if err := supabaseSelect("room_feed_secret?select=*&room_id=eq."+url.QueryEscape(roomID), &secrets); err != nil {
+		return nil, err
+	}
+	if len(secrets) == 0 {
+		return nil, nil
+	}
+	return &secrets[0], nil
+}
+
+// SaveRoomFeedSecret ルームの購読フィードの秘密の値を登録・置き換える（以前のURLは使えなくなる）
+func SaveRoomFeedSecret(secret RoomFeedSecret) error {
+	_, err := supabaseRequest("POST", "room_feed_secret?on_conflict=room_id", secret,
+		"resolution=merge-duplicates,return=minimal")
+	return err
+}
+
+// DeleteRoomFeedSecret ルームの購読フィードの秘密の値を削除する
+func DeleteRoomFeedSecret(roomID string) error {
+	_, err := supabaseRequest("DELETE", "room_feed_secret?room_id=eq."+url.QueryEscape(roomID), nil, "return=minimal")
+	return err
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"net/url"
+	"time"
+)
+
+// QuietPeriod 配信を止める期間
+// "15:04" 形式の場合は毎日の時間帯（日をまたいでもよい）、"2006-01-02" 形式の場合は日付の範囲（両端を含む）
+type QuietPeriod struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// RoomSettings ルームごとの配信設定
+type RoomSettings struct {
+	RoomID          string        `json:"room_id"`
+	DeliveryEnabled bool          `json:"delivery_enabled"`
+	TimeZone        string        `json:"timezone"`
+	DeliveryCron    []string      `json:"delivery_cron"`
+	MaxArticles     int           `json:"max_articles"`
+	QuietPeriods    []QuietPeriod `json:"quiet_periods"`
+	UpdatedAt       *time.Time    `json:"updated_at,omitempty"`
+}
+
+// FetchRoomSettings ルームの配信設定を取得（未設定の場合はnil）
+func FetchRoomSettings(roomID string) (*RoomSettings, error) {
+	var settings []RoomSettings
+	if err := supabaseSelect("room_settings?select=*&room_id=eq."+url.QueryEscape(roomID), &settings); err != nil {
+		return nil, err
+	}
+	if len(settings) == 0 {
+		return nil, nil
+	}
+	return &settings[0], nil
+}
+
+// SaveRoomSettings ルームの配信設定を登録・更新
+func SaveRoomSettings(settings RoomSettings) error {
+	now := time.Now().UTC()
+	settings.UpdatedAt = &now
+	_, err := supabaseRequest("POST", "room_settings?on_conflict=room_id", settings,
+		"resolution=merge-duplicates,return=minimal")
+	return err
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// FetchDeliveredLogs ルームのsince以降に配信に成功した記録を取得
+func FetchDeliveredLogs(roomID string, since time.Time) ([]DeliveryLog, error) {
+	path := fmt.Sprintf("delivery_log?select=*&room_id=eq.%s&status=eq.%s&attempted_at=gte.%s",
+		url.QueryEscape(roomID), DeliveryStatusSucceeded, url.QueryEscape(since.UTC().Format(time.RFC3339)))
+
+	var logs []DeliveryLog
+	if err := supabaseSelect(path, &logs); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// FetchArticleClicks ルームのsince以降のクリックを取得
+func FetchArticleClicks(roomID string, since time.Time) ([]ArticleClick, error) {
+	path := fmt.Sprintf("article_click?select=*&room_id=eq.%s&clicked_at=gte.%s",
+		url.QueryEscape(roomID), url.QueryEscape(since.UTC().Format(time.RFC3339)))
+
+	var clicks []ArticleClick
+	if err := supabaseSelect(path, &clicks); err != nil {
+		return nil, err
+	}
+	return clicks, nil
+}
+
+// FetchHistorySince ルームのsince以降に記録された履歴を取得（本文は含まない）
+func FetchHistorySince(roomID string, since time.Time) ([]ArticleHistory, error) {
+	path := fmt.Sprintf("article_history?select=room_id,article_id,matched_fields,score,delivered_at,deleted_at,reserved_at,recorded_at&room_id=eq.%s&recorded_at=gte.%s",
+		url.QueryEscape(roomID), url.QueryEscape(since.UTC().Format(time.RFC3339)))
+
+	var histories []ArticleHistory
+	if err := supabaseSelect(path, &histories); err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
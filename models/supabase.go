@@ -0,0 +1,120 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// supabaseRequest Supabase REST APIへリクエストを送信し、レスポンスボディを返す
+// pathは "/rest/v1/" 以降（クエリ文字列を含む）を指定する
+func supabaseRequest(method, path string, body interface{}, prefer string) ([]byte, error) {
+	respBody, _, err := supabaseDo(method, path, body, prefer)
+	return respBody, err
+}
+
+// supabaseDo Supabase REST APIへリクエストを送信し、レスポンスボディとヘッダーを返す
+func supabaseDo(method, path string, body interface{}, prefer string) ([]byte, http.Header, error) {
+	supabaseURL := os.Getenv("SUPABASE_URL")
+	supabaseKey := os.Getenv("SUPABASE_KEY")
+
+	var reader io.Reader
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("JSONの生成に失敗しました: %w", err)
+		}
+		reader = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequest(method, supabaseURL+"/rest/v1/"+path, reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("リクエストの作成に失敗しました: %w", err)
+	}
+
+	req.Header.Set("apikey", supabaseKey)
+	req.Header.Set("Authorization", "Bearer "+supabaseKey)
+	req.Header.Set("Content-Type", "application/json")
+	if prefer != "" {
+		req.Header.Set("Prefer", prefer)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("APIリクエストに失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("レスポンスの読み取りに失敗しました: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, nil, fmt.Errorf("Supabaseエラー - Status: %d, Body: %s", resp.StatusCode, string(respBody))
+	}
+
+	return respBody, resp.Header, nil
+}
+
+// supabaseSelect GETリクエストを送信し、結果をoutにデコードする
+func supabaseSelect(path string, out interface{}) error {
+	body, err := supabaseRequest("GET", path, nil, "")
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("JSONのパースに失敗しました: %w", err)
+	}
+	return nil
+}
+
+// inFilter PostgRESTの in.(...) フィルタ用に値を引用符付きで連結する
+func inFilter(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = quoteFilterValue(v)
+	}
+	return url.QueryEscape("(" + strings.Join(quoted, ",") + ")")
+}
+
+// supabaseSelectPage GETリクエストを送信して結果をoutにデコードし、条件に一致する全件数を返す
+func supabaseSelectPage(path string, out interface{}) (int, error) {
+	body, header, err := supabaseDo("GET", path, nil, "count=exact")
+	if err != nil {
+		return 0, err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return 0, fmt.Errorf("JSONのパースに失敗しました: %w", err)
+	}
+
+	// Content-Range: 0-19/123 の形式で全件数が返される
+	total := 0
+	if contentRange := header.Get("Content-Range"); contentRange != "" {
+		if i := strings.LastIndex(contentRange, "/"); i >= 0 {
+			total, _ = strconv.Atoi(contentRange[i+1:])
+		}
+	}
+	return total, nil
+}
+
+// likePattern キーワードの部分一致に使うilikeフィルタのパターンを作る
+// キーワードの \ % _ はLIKEの特殊文字として扱わないようにエスケープする
+// PostgRESTはパターン中の * をすべて % に置き換えるため、キーワードの * は任意の1文字（_）として扱う
+func likePattern(keyword string) string {
+	keyword = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`, `*`, `_`).Replace(keyword)
+	return "*" + keyword + "*"
+}
+
+// quoteFilterValue PostgRESTの or=(...) などで使う値を引用符で囲む
+func quoteFilterValue(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `"`, `\"`)
+	return `"` + v + `"`
+}
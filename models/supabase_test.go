@@ -0,0 +1,18 @@
+package models
+
+import "testing"
+
+func TestLikePattern(t *testing.T) {
+	tests := map[string]string{
+		"Go":      "*Go*",
+		"100%":    `*100\%*`,
+		"a_b":     `*a\_b*`,
+		`C:\temp`: `*C:\\temp*`,
+		"a*b":     "*a_b*",
+	}
+	for in, want := range tests {
+		if got := likePattern(in); got != want {
+			t.Errorf("likePattern(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
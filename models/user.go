@@ -54,3 +54,28 @@ func (u *User) Authenticate() bool {
 
 	return false
 }
+
+// RoomExists 指定したroom_idが登録済みのルームかどうか
+func RoomExists(roomID string) bool {
+	if roomID == "" {
+		return false
+	}
+	user := &User{RoomID: roomID}
+	return user.Authenticate()
+}
+
+// FetchRoomIDs 登録済みのすべてのroom_idを取得
+func FetchRoomIDs() ([]string, error) {
+	var users []struct {
+		RoomID string `json:"room_id"`
+	}
+	if err := supabaseSelect("user?select=room_id", &users); err != nil {
+		return nil, err
+	}
+
+	roomIDs := make([]string, 0, len(users))
+	for _, user := range users {
+		roomIDs = append(roomIDs, user.RoomID)
+	}
+	return roomIDs, nil
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"login-app/models"
+)
+
+// 記事のエクスポートで1回に読み込む件数
+const articleExportPageSize = 200
+
+// 記事のエクスポートの形式
+const (
+	ExportFormatMarkdown = "md"
+	ExportFormatCSV      = "csv"
+	ExportFormatJSON     = "json"
+)
+
+// ExportStatusDeleted 配信せずに削除した記事の状態（履歴にだけ残っている）
+const ExportStatusDeleted = "deleted"
+
+// ExportedArticle エクスポートする記事1件（日時はルームのタイムゾーンで表す）
+type ExportedArticle struct {
+	ArticleID     int64    `json:"article_id"`
+	Title         string   `json:"title"`
+	URL           string   `json:"url"`
+	Summary       string   `json:"summary"`
+	MatchedFields []string `json:"matched_fields"`
+	Tags          []string `json:"tags"`
+	Score         float64  `json:"score"`
+	Status        string   `json:"status"` // "reserved"、"delivered" または "deleted"
+	CreatedAt     string   `json:"created_at,omitempty"`
+	DeliveredAt   string   `json:"delivered_at,omitempty"`
+	DeletedAt     string   `json:"deleted_at,omitempty"`
+}
+
+// ArticleExportQuery 記事のエクスポートの絞り込み条件を作成する
+// statusはreserved・delivered、since・untilはルームのタイムゾーンの日付（YYYY-MM-DD、untilの日を含む）
+func ArticleExportQuery(roomID, status, field, keyword, since, until string) (models.ArticleQuery, error) {
+	var q models.ArticleQuery
+	switch status {
+	case "":
+	case RoomFeedStatusReserved:
+		delivered := false
+		q.Delivered = &delivered
+	case RoomFeedStatusDelivered:
+		delivered := true
+		q.Delivered = &delivered
+	default:
+		return q, fmt.Errorf("statusにはreservedまたはdeliveredを指定してください")
+	}
+	q.Field = models.NormalizeFieldName(field)
+	q.Keyword = keyword
+
+	if since == "" && until == "" {
+		return q, nil
+	}
+	loc, _, err := roomLocation(roomID)
+	if err != nil {
+		return q, err
+	}
+	if since != "" {
+		t, err := time.ParseInLocation("2006-01-02", since, loc)
+		if err != nil {
+			return q, fmt.Errorf("sinceはYYYY-MM-DDの形式で指定してください")
+		}
+		q.Since = &t
+	}
+	if until != "" {
+		t, err := time.ParseInLocation("2006-01-02", until, loc)
+		if err != nil {
+			return q, fmt.Errorf("untilはYYYY-MM-DDの形式で指定してください")
+		}
+		t = t.AddDate(0, 0, 1)
+		q.Until = &t
+	}
+	if q.Since != nil && q.Until != nil && !q.Since.Before(*q.Until) {
+		return q, fmt.Errorf("sinceにはuntil以前の日付を指定してください")
+	}
+	return q, nil
+}
+
+// ArticleExportWriter 記事を1件ずつ書き出す
+type ArticleExportWriter interface {
+	Begin() error
+	Write(article ExportedArticle) error
+	End() error
+}
+
+// NewArticleExportWriter 形式に応じたArticleExportWriterとContent-Typeを返す
+func NewArticleExportWriter(w io.Writer, format, roomID string) (ArticleExportWriter, string, error) {
+	switch format {
+	case ExportFormatMarkdown:
+		return &markdownExportWriter{w: w, roomID: roomID}, "text/markdown; charset=UTF-8", nil
+	case ExportFormatCSV:
+		return &csvExportWriter{w: csv.NewWriter(w)}, "text/csv; charset=UTF-8", nil
+	case ExportFormatJSON:
+		return &jsonExportWriter{w: w, roomID: roomID}, "application/json; charset=UTF-8", nil
+	}
+	return nil, "", fmt.Errorf("formatにはmd、csv、jsonのいずれかを指定してください")
+}
+
+// ExportArticles 条件に一致するルームの記事を記事IDの順に書き出す
+// 一定の件数を読み込むごとに書き出してflushを呼ぶため、全件をメモリに載せない
+func ExportArticles(roomID string, q models.ArticleQuery, out ArticleExportWriter, flush func()) error {
+	loc, _, err := roomLocation(roomID)
+	if err != nil {
+		return err
+	}
+
+	if err := out.Begin(); err != nil {
+		return err
+	}
+	err = models.ScanArticles(roomID, q, articleExportPageSize, func(articles []models.Article) error {
+		for _, article := range articles {
+			if err := out.Write(exportedArticle(article, loc)); err != nil {
+				return err
+			}
+		}
+		if flush != nil {
+			flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return out.End()
+}
+
+func exportedArticle(article models.Article, loc *time.Location) ExportedArticle {
+	parsed := models.ParseArticleContent(article.Content)
+	exported := ExportedArticle{
+		ArticleID:     article.ArticleID,
+		Title:         parsed.Title,
+		URL:           parsed.URL,
+		Summary:       parsed.Summary,
+		MatchedFields: article.MatchedFields,
+		Tags:          parsed.Tags,
+		Score:         article.Score,
+		Status:        RoomFeedStatusReserved,
+	}
+	if exported.MatchedFields == nil {
+		exported.MatchedFields = []string{}
+	}
+	if exported.Tags == nil {
+		exported.Tags = []string{}
+	}
+	if article.CreatedAt != nil {
+		exported.CreatedAt = article.CreatedAt.In(loc).Format(time.RFC3339)
+	}
+	if article.DeletedAt != nil {
+		exported.Status = ExportStatusDeleted
+		exported.DeletedAt = article.DeletedAt.In(loc).Format(time.RFC3339)
+	}
+	if article.DeliveredAt != nil {
+		exported.Status = RoomFeedStatusDelivered
+		exported.DeliveredAt = article.DeliveredAt.In(loc).Format(time.RFC3339)
+	}
+	return exported
+}
+
+// csvExportWriter 1行目に列名を書き、複数の値は「、」で区切る
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func (e *csvExportWriter) Begin() error {
+	return e.w.Write([]string{"article_id", "title", "url", "summary", "matched_fields", "tags", "score", "status", "created_at", "delivered_at", "deleted_at"})
+}
+
+func (e *csvExportWriter) Write(a ExportedArticle) error {
+	return e.w.Write([]string{
+		strconv.FormatInt(a.ArticleID, 10),
+		CSVText(a.Title),
+		CSVText(a.URL),
+		CSVText(a.Summary),
+		CSVText(strings.Join(a.MatchedFields, "、")),
+		CSVText(strings.Join(a.Tags, "、")),
+		strconv.FormatFloat(a.Score, 'f', -1, 64),
+		a.Status,
+		a.CreatedAt,
+		a.DeliveredAt,
+		a.DeletedAt,
+	})
+}
+
+// csvFormulaPrefixes 表計算ソフトでセルの先頭にあると数式として解釈される文字
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// CSVText 表計算ソフトで数式として解釈される文字で始まるセルの先頭に "'" を付ける
+// タイトルや要約、分野名は利用者や外部のフィード・ページから取り込んだものをそのまま含むため
+func CSVText(s string) string {
+	if s != "" && strings.ContainsRune(csvFormulaPrefixes, rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// ParseCSVText CSVTextで付けた先頭の "'" を取り除く（書き出したCSVを読み込み直す場合に使う）
+func ParseCSVText(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(s[1])) {
+		return s[1:]
+	}
+	return s
+}
+
+func (e *csvExportWriter) End() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonExportWriter {"room_id": ..., "articles": [...]} を記事ごとに書き出す
+type jsonExportWriter struct {
+	w      io.Writer
+	roomID string
+	count  int
+}
+
+func (e *jsonExportWriter) Begin() error {
+	room, err := json.Marshal(e.roomID)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(e.w, "{\"room_id\":%s,\"articles\":[", room)
+	return err
+}
+
+func (e *jsonExportWriter) Write(a ExportedArticle) error {
+	b, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	separator := "\n"
+	if e.count > 0 {
+		separator = ",\n"
+	}
+	e.count++
+	if _, err := io.WriteString(e.w, separator); err != nil {
+		return err
+	}
+	_, err = e.w.Write(b)
+	return err
+}
+
+func (e *jsonExportWriter) End() error {
+	_, err := io.WriteString(e.w, "\n]}\n")
+	return err
+}
+
+// markdownExportWriter 記事ごとに見出しと箇条書きで書き出す（Wikiへの貼り付け用）
+type markdownExportWriter struct {
+	w      io.Writer
+	roomID string
+}
+
+// Markdownの記法として解釈される文字
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "#", `\#`, "|", `\|`,
+)
+
+// Markdownのリンク先で区切りと誤認される文字
+var markdownURLEscaper = strings.NewReplacer("(", "%28", ")", "%29", " ", "%20", "<", "%3C", ">", "%3E")
+
+func (e *markdownExportWriter) Begin() error {
+	_, err := fmt.Fprintf(e.w, "# ルーム %s の記事\n", markdownEscaper.Replace(e.roomID))
+	return err
+}
+
+func (e *markdownExportWriter) Write(a ExportedArticle) error {
+	var b strings.Builder
+	title := a.Title
+	if title == "" {
+		title = "タイトルなし"
+	}
+	b.WriteString("\n## ")
+	if a.URL != "" {
+		fmt.Fprintf(&b, "[%s](%s)\n\n", markdownEscaper.Replace(title), markdownURLEscaper.Replace(a.URL))
+	} else {
+		b.WriteString(markdownEscaper.Replace(title) + "\n\n")
+	}
+
+	status := "予約中"
+	switch a.Status {
+	case RoomFeedStatusDelivered:
+		status = "配信済み"
+	case ExportStatusDeleted:
+		status = "削除済み"
+	}
+	fmt.Fprintf(&b, "- 記事ID: %d\n", a.ArticleID)
+	fmt.Fprintf(&b, "- 状態: %s\n", status)
+	if a.CreatedAt != "" {
+		fmt.Fprintf(&b, "- 予約日時: %s\n", a.CreatedAt)
+	}
+	if a.DeliveredAt != "" {
+		fmt.Fprintf(&b, "- 配信日時: %s\n", a.DeliveredAt)
+	}
+	if a.DeletedAt != "" {
+		fmt.Fprintf(&b, "- 削除日時: %s\n", a.DeletedAt)
+	}
+	if len(a.MatchedFields) > 0 {
+		fmt.Fprintf(&b, "- 分野: %s\n", markdownEscaper.Replace(strings.Join(a.MatchedFields, "、")))
+	}
+	if len(a.Tags) > 0 {
+		fmt.Fprintf(&b, "- タグ: %s\n", markdownEscaper.Replace(strings.Join(a.Tags, "、")))
+	}
+	if summary := strings.TrimSpace(a.Summary); summary != "" {
+		b.WriteString("\n")
+		for _, line := range strings.Split(summary, "\n") {
+			b.WriteString("> " + markdownEscaper.Replace(line) + "\n")
+		}
+	}
+
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+func (e *markdownExportWriter) End() error {
+	return nil
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"login-app/models"
+)
+
+// recordExportWriter 書き出した記事を記録するArticleExportWriter
+type recordExportWriter struct {
+	articles []ExportedArticle
+}
+
+func (r *recordExportWriter) Begin() error { return nil }
+
+func (r *recordExportWriter) Write(a ExportedArticle) error {
+	r.articles = append(r.articles, a)
+	return nil
+}
+
+func (r *recordExportWriter) End() error { return nil }
+
+func TestExportArticlesIncludesHistory(t *testing.T) {
+	db := newFakeSupabase(t)
+	delivered := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	deleted := delivered.Add(time.Hour)
+	reserved := testArticle(2, models.RenderArticleContent(models.ArticleContent{Title: "予約中", URL: "https://example.com/2"}))
+	reserved.DeliveredAt = &delivered
+	db.Set("reserve_article", []models.Article{
+		testArticle(1, models.RenderArticleContent(models.ArticleContent{Title: "未配信", URL: "https://example.com/1"})),
+		reserved,
+	})
+	db.Set("article_history", []models.ArticleHistory{
+		{RoomID: "1", ArticleID: 2, Content: "重複", DeliveredAt: &delivered},
+		{RoomID: "1", ArticleID: 3, Content: models.RenderArticleContent(models.ArticleContent{Title: "削除済み", URL: "https://example.com/3"}), DeletedAt: &deleted},
+	})
+
+	out := &recordExportWriter{}
+	if err := ExportArticles("1", models.ArticleQuery{}, out, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.articles) != 3 {
+		t.Fatalf("reserve_articleと履歴の記事を1件ずつ書き出す: %+v", out.articles)
+	}
+	if out.articles[1].Title != "予約中" {
+		t.Errorf("reserve_articleで書き出した記事は履歴から書き出さない: %+v", out.articles[1])
+	}
+	last := out.articles[2]
+	if last.ArticleID != 3 || last.Title != "削除済み" || last.Status != ExportStatusDeleted || last.DeletedAt == "" {
+		t.Errorf("削除した記事を履歴から書き出す: %+v", last)
+	}
+
+	undelivered := false
+	out = &recordExportWriter{}
+	if err := ExportArticles("1", models.ArticleQuery{Delivered: &undelivered}, out, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.articles) != 2 {
+		t.Errorf("未配信の記事だけを指定した場合は履歴を読まない: %+v", out.articles)
+	}
+}
+
+func TestCSVText(t *testing.T) {
+	tests := map[string]string{
+		"=HYPERLINK(\"x\")": "'=HYPERLINK(\"x\")",
+		"@SUM(A1)":          "'@SUM(A1)",
+		"-1":                "'-1",
+		"Go":                "Go",
+		"'quoted":           "'quoted",
+		"":                  "",
+	}
+	for in, want := range tests {
+		got := CSVText(in)
+		if got != want {
+			t.Errorf("CSVText(%q) = %q, want %q", in, got, want)
+		}
+		if back := ParseCSVText(got); back != in {
+			t.Errorf("ParseCSVText(%q) = %q, want %q", got, back, in)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"login-app/models"
+)
+
+// 短縮URLの展開に使う設定
+const shortURLResolveTimeout = 10 * time.Second
+
+// shortURLHosts 転送先をHTTPで確認する必要がある短縮URLのホスト
+var shortURLHosts = map[string]bool{
+	"t.co": true, "bit.ly": true, "bitly.com": true, "goo.gl": true, "ow.ly": true, "buff.ly": true,
+	"lnkd.in": true, "tinyurl.com": true, "is.gd": true, "amzn.to": true, "amzn.asia": true,
+	"dlvr.it": true, "ift.tt": true, "htn.to": true, "nkbp.jp": true, "s.nikkei.com": true,
+	"trib.al": true, "fb.me": true,
+}
+
+// URLCanonicalizer 記事URLを保存用の正規の形にする
+// 既知の短縮URLは転送先を取得してから正規化する
+type URLCanonicalizer struct {
+	client *http.Client
+}
+
+// NewURLCanonicalizer 正規化の処理を作成
+// clientにはNewSafeHTTPClientで作成した内部ネットワークに接続しないクライアントを渡す
+func NewURLCanonicalizer(client *http.Client) *URLCanonicalizer {
+	return &URLCanonicalizer{client: client}
+}
+
+// Canonicalize URLを正規化する
+// 短縮URLの展開に失敗した場合は、展開せずに正規化したURLを返す
+func (uc *URLCanonicalizer) Canonicalize(ctx context.Context, raw string) (string, error) {
+	canonical, err := models.CanonicalizeArticleURL(raw)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(canonical)
+	if err != nil || !shortURLHosts[u.Hostname()] {
+		return canonical, nil
+	}
+
+	resolved, err := uc.resolve(ctx, canonical)
+	if err != nil {
+		fmt.Printf("短縮URLの展開エラー - URL: %s, Error: %v\n", canonical, err)
+		return canonical, nil
+	}
+	if c, err := models.CanonicalizeArticleURL(resolved); err == nil {
+		return c, nil
+	}
+	return canonical, nil
+}
+
+// resolve リダイレクトをたどった最終的なURLを返す（HEADが使えない場合はGETで確認する）
+func (uc *URLCanonicalizer) resolve(ctx context.Context, shortURL string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, shortURLResolveTimeout)
+	defer cancel()
+
+	var lastErr error
+	for _, method := range []string{"HEAD", "GET"} {
+		req, err := http.NewRequestWithContext(ctx, method, shortURL, nil)
+		if err != nil {
+			return "", err
+		}
+		resp, err := uc.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 400 {
+			lastErr = fmt.Errorf("Status: %d", resp.StatusCode)
+			continue
+		}
+		return resp.Request.URL.String(), nil
+	}
+	return "", lastErr
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"login-app/models"
+)
+
+// 署名の長さ（HMAC-SHA256の先頭16バイト）
+const linkSignatureSize = 16
+
+// 転送用リンクの有効期間
+const linkTokenTTL = 180 * 24 * time.Hour
+
+var (
+	// ErrInvalidLinkToken 転送用のトークンが改ざんされているか形式が正しくない場合のエラー
+	ErrInvalidLinkToken = errors.New("リンクが正しくありません")
+	// ErrExpiredLinkToken 転送用のトークンの有効期限が切れている場合のエラー
+	ErrExpiredLinkToken = errors.New("リンクの有効期限が切れています")
+)
+
+// ClickTarget 転送用リンクが指す記事と転送先のURL
+type ClickTarget struct {
+	RoomID    string `json:"r"`
+	ArticleID int64  `json:"a"`
+	URL       string `json:"u"`
+	Source    string `json:"s"`
+	ExpiresAt int64  `json:"e,omitempty"` // 有効期限（Unix時刻）
+}
+
+// LinkSigner クリックを記録する転送用リンク（/r/{token}）を作成・検証する
+// トークンは転送先を含むJSONにHMACの署名を付けたもので、署名のないURLへは転送しない（オープンリダイレクト対策）
+type LinkSigner struct {
+	key     []byte
+	baseURL string
+}
+
+// NewLinkSigner 署名の鍵と、配信メッセージに載せる絶対URLの元になる公開URLを指定して作成
+// baseURLが空の場合は配信メッセージのリンクを書き換えない
+func NewLinkSigner(key []byte, baseURL string) *LinkSigner {
+	return &LinkSigner{key: key, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// LinkSignerFromEnv 環境変数LINK_SIGNING_KEY・PUBLIC_BASE_URLから作成
+// LINK_SIGNING_KEYが未設定の場合はfallbackKey（環境変数で設定したセッションの鍵）を使う
+// どちらも設定されていない場合は誰でも知っている既定の鍵で署名しないよう、起動ごとにランダムな鍵を作る
+// （再起動すると以前に配信したリンクは転送できなくなる）
+func LinkSignerFromEnv(fallbackKey string) *LinkSigner {
+	key := []byte(os.Getenv("LINK_SIGNING_KEY"))
+	if len(key) == 0 {
+		key = []byte(fallbackKey)
+	}
+	if len(key) == 0 {
+		fmt.Printf("警告: LINK_SIGNING_KEYとSESSION_SECRETが設定されていないため、転送用リンクの署名に一時的な鍵を使います。再起動すると配信済みのリンクは使えなくなります\n")
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			panic(fmt.Sprintf("転送用リンクの鍵の作成に失敗しました: %v", err))
+		}
+	}
+	return NewLinkSigner(key, os.Getenv("PUBLIC_BASE_URL"))
+}
+
+// Sign 転送先に署名したトークンを作成（有効期限を指定していない場合はlinkTokenTTL後に期限切れにする）
+func (s *LinkSigner) Sign(target ClickTarget) string {
+	if target.ExpiresAt == 0 {
+		target.ExpiresAt = time.Now().Add(linkTokenTTL).Unix()
+	}
+	payload, _ := json.Marshal(target)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.signature(encoded))
+}
+
+// Verify トークンの署名を検証し、転送先を返す
+func (s *LinkSigner) Verify(token string) (ClickTarget, error) {
+	var target ClickTarget
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return target, ErrInvalidLinkToken
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, s.signature(encoded)) {
+		return target, ErrInvalidLinkToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || json.Unmarshal(payload, &target) != nil {
+		return target, ErrInvalidLinkToken
+	}
+	if !strings.HasPrefix(target.URL, "http://") && !strings.HasPrefix(target.URL, "https://") {
+		return target, ErrInvalidLinkToken
+	}
+	// 有効期限のないトークンは期限を付ける前に配信したもの
+	if target.ExpiresAt != 0 && time.Now().Unix() > target.ExpiresAt {
+		return target, ErrExpiredLinkToken
+	}
+	return target, nil
+}
+
+func (s *LinkSigner) signature(encoded string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)[:linkSignatureSize]
+}
+
+// Path 転送用リンクのパス（記事一覧ページなど同じサイト内で使う）
+func (s *LinkSigner) Path(target ClickTarget) string {
+	return "/r/" + s.Sign(target)
+}
+
+// TrackedContent 配信するメッセージの記事URLを転送用リンクに書き換える
+// 公開URLが設定されていない場合や記事にURLがない場合はそのまま返す
+func (s *LinkSigner) TrackedContent(article models.Article) string {
+	if s == nil || s.baseURL == "" {
+		return article.Content
+	}
+	articleURL := models.ParseArticleContent(article.Content).URL
+	if articleURL == "" {
+		return article.Content
+	}
+
+	link := s.baseURL + s.Path(ClickTarget{
+		RoomID:    article.RoomID,
+		ArticleID: article.ArticleID,
+		URL:       articleURL,
+		Source:    models.ClickSourceChatwork,
+	})
+	return strings.Replace(article.Content, articleURL, link, 1)
+}
+
+// AnonymizeUserAgent User-Agentを利用者を特定できない粒度（ブラウザとOSの種類）にする
+// リンクのプレビューを取得するボットは "bot" を返す
+func AnonymizeUserAgent(ua string) string {
+	lower := strings.ToLower(ua)
+	if lower == "" {
+		return "unknown"
+	}
+	for _, marker := range []string{"bot", "crawler", "spider", "preview", "curl", "wget", "python", "go-http-client", "headless"} {
+		if strings.Contains(lower, marker) {
+			return "bot"
+		}
+	}
+
+	browser := "other"
+	switch {
+	case strings.Contains(lower, "edg/"):
+		browser = "Edge"
+	case strings.Contains(lower, "chatwork"):
+		browser = "Chatwork"
+	case strings.Contains(lower, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(lower, "chrome/") || strings.Contains(lower, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(lower, "safari/"):
+		browser = "Safari"
+	}
+
+	platform := "other"
+	switch {
+	case strings.Contains(lower, "iphone") || strings.Contains(lower, "ipad"):
+		platform = "iOS"
+	case strings.Contains(lower, "android"):
+		platform = "Android"
+	case strings.Contains(lower, "windows"):
+		platform = "Windows"
+	case strings.Contains(lower, "mac os"):
+		platform = "macOS"
+	case strings.Contains(lower, "linux"):
+		platform = "Linux"
+	}
+	return browser + "/" + platform
+}
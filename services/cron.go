@@ -0,0 +1,125 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSpec 5項目（分 時 日 月 曜日）のcron形式の配信時刻
+// 各項目は "*"、数値、範囲 "1-5"、リスト "1,3,5"、間隔 "*/15" に対応する
+type CronSpec struct {
+	expr     string
+	minutes  [60]bool
+	hours    [24]bool
+	days     [32]bool
+	months   [13]bool
+	weekdays [7]bool
+	anyDay   bool
+	anyWeek  bool
+}
+
+// ParseCron cron形式の文字列を解析する
+func ParseCron(expr string) (CronSpec, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return CronSpec{}, fmt.Errorf("cron形式 %q は「分 時 日 月 曜日」の5項目で指定してください", expr)
+	}
+
+	spec := CronSpec{expr: strings.Join(fields, " ")}
+	parsers := []struct {
+		name     string
+		min, max int
+		set      func(int)
+	}{
+		{"分", 0, 59, func(v int) { spec.minutes[v] = true }},
+		{"時", 0, 23, func(v int) { spec.hours[v] = true }},
+		{"日", 1, 31, func(v int) { spec.days[v] = true }},
+		{"月", 1, 12, func(v int) { spec.months[v] = true }},
+		// 曜日は0と7のどちらも日曜日として扱う
+		{"曜日", 0, 7, func(v int) { spec.weekdays[v%7] = true }},
+	}
+
+	for i, p := range parsers {
+		if err := parseCronField(fields[i], p.min, p.max, p.set); err != nil {
+			return CronSpec{}, fmt.Errorf("cron形式 %q の%sが正しくありません: %v", expr, p.name, err)
+		}
+	}
+	spec.anyDay = fields[2] == "*"
+	spec.anyWeek = fields[4] == "*"
+
+	return spec, nil
+}
+
+// String 正規化したcron形式の文字列
+func (c CronSpec) String() string {
+	return c.expr
+}
+
+// Matches tの時刻（分単位）がcronに一致するかどうか（tのタイムゾーンで判定する）
+// 一般的なcronと同様に、日と曜日の両方が指定されている場合はどちらかに一致すればよい
+func (c CronSpec) Matches(t time.Time) bool {
+	if !c.minutes[t.Minute()] || !c.hours[t.Hour()] || !c.months[int(t.Month())] {
+		return false
+	}
+
+	dayMatch := c.days[t.Day()]
+	weekMatch := c.weekdays[int(t.Weekday())]
+	switch {
+	case c.anyDay && c.anyWeek:
+		return true
+	case c.anyDay:
+		return weekMatch
+	case c.anyWeek:
+		return dayMatch
+	default:
+		return dayMatch || weekMatch
+	}
+}
+
+// parseCronField cronの1項目を解析し、該当する値をsetに渡す
+func parseCronField(field string, min, max int, set func(int)) error {
+	for _, part := range strings.Split(field, ",") {
+		step, hasStep := 1, false
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return fmt.Errorf("間隔 %q", part)
+			}
+			step, hasStep = n, true
+			part = part[:i]
+		}
+
+		lo, hi := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(bounds[0])
+			hi, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil || lo > hi {
+				return fmt.Errorf("範囲 %q", part)
+			}
+		default:
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return fmt.Errorf("値 %q", part)
+			}
+			lo, hi = n, n
+			// 一般的なcronと同様に "N/step" はNから最大値までstepごととする
+			if hasStep {
+				hi = max
+			}
+		}
+
+		if lo < min || hi > max {
+			return fmt.Errorf("%q は%dから%dの範囲で指定してください", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			set(v)
+		}
+	}
+	return nil
+}
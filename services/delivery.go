@@ -0,0 +1,410 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"login-app/chatwork"
+	"login-app/models"
+)
+
+// 配信の既定値と再試行の設定
+const (
+	DefaultDeliveryTimeZone   = "Asia/Tokyo"
+	DefaultDeliveryCron       = "0 9 * * *"
+	DefaultMaxArticles        = 3
+	MaxArticlesLimit          = 20
+	maxDeliveryAttempts       = 5
+	deliveryRetryBaseDelay    = 5 * time.Minute
+	deliverySchedulerInterval = time.Minute
+	deliveryCatchUpWindow     = 24 * time.Hour // 取りこぼした枠をさかのぼって配信する期間
+	deliveryRequestTimeout    = 30 * time.Second
+)
+
+// DeliverySchedule ルームごとの配信スケジュール
+type DeliverySchedule struct {
+	Enabled      bool
+	Location     *time.Location
+	Crons        []CronSpec
+	MaxArticles  int
+	QuietPeriods []models.QuietPeriod
+}
+
+// Quiet tが配信停止期間に含まれるかどうか
+func (s DeliverySchedule) Quiet(t time.Time) bool {
+	local := t.In(s.Location)
+	for _, q := range s.QuietPeriods {
+		if quietPeriodContains(q, local) {
+			return true
+		}
+	}
+	return false
+}
+
+// Due tがスケジュールの配信時刻（分単位）に当たるかどうかと、その枠を表すキーを返す
+func (s DeliverySchedule) Due(t time.Time) (string, bool) {
+	if !s.Enabled || s.Quiet(t) {
+		return "", false
+	}
+
+	local := t.In(s.Location)
+	for _, cron := range s.Crons {
+		if cron.Matches(local) {
+			return local.Format("2006-01-02 15:04"), true
+		}
+	}
+	return "", false
+}
+
+// LatestSlot afterより後、now以前で最も新しい配信時刻（分単位）を返す
+func (s DeliverySchedule) LatestSlot(after, now time.Time) (time.Time, bool) {
+	for t := now.Truncate(time.Minute); t.After(after); t = t.Add(-time.Minute) {
+		if _, due := s.Due(t); due {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// DefaultRoomSettings 環境変数（DELIVERY_CRON、DELIVERY_MAX_ARTICLES、DELIVERY_TIMEZONE）から、
+// 配信設定が未登録のルームに使う既定の設定を作る
+func DefaultRoomSettings() models.RoomSettings {
+	settings := models.RoomSettings{
+		DeliveryEnabled: true,
+		TimeZone:        os.Getenv("DELIVERY_TIMEZONE"),
+		MaxArticles:     DefaultMaxArticles,
+		QuietPeriods:    []models.QuietPeriod{},
+	}
+	if settings.TimeZone == "" {
+		settings.TimeZone = DefaultDeliveryTimeZone
+	}
+
+	crons := os.Getenv("DELIVERY_CRON")
+	if crons == "" {
+		crons = DefaultDeliveryCron
+	}
+	for _, expr := range strings.Split(crons, ";") {
+		settings.DeliveryCron = append(settings.DeliveryCron, strings.TrimSpace(expr))
+	}
+
+	if max, err := strconv.Atoi(os.Getenv("DELIVERY_MAX_ARTICLES")); err == nil {
+		settings.MaxArticles = max
+	}
+
+	return settings
+}
+
+// DefaultDeliverySchedule 既定の設定を検証してスケジュールに変換する
+func DefaultDeliverySchedule() (DeliverySchedule, error) {
+	return ScheduleFromSettings(DefaultRoomSettings())
+}
+
+// ScheduleFromSettings ルームの配信設定を検証してスケジュールに変換する
+func ScheduleFromSettings(settings models.RoomSettings) (DeliverySchedule, error) {
+	loc, err := time.LoadLocation(settings.TimeZone)
+	if err != nil || settings.TimeZone == "" {
+		return DeliverySchedule{}, fmt.Errorf("タイムゾーン %q を読み込めません", settings.TimeZone)
+	}
+
+	if settings.MaxArticles < 1 || settings.MaxArticles > MaxArticlesLimit {
+		return DeliverySchedule{}, fmt.Errorf("1回の配信件数は1から%dの間で指定してください", MaxArticlesLimit)
+	}
+
+	schedule := DeliverySchedule{
+		Enabled:      settings.DeliveryEnabled,
+		Location:     loc,
+		MaxArticles:  settings.MaxArticles,
+		QuietPeriods: make([]models.QuietPeriod, 0, len(settings.QuietPeriods)),
+	}
+
+	for _, expr := range settings.DeliveryCron {
+		cron, err := ParseCron(expr)
+		if err != nil {
+			return DeliverySchedule{}, err
+		}
+		schedule.Crons = append(schedule.Crons, cron)
+	}
+
+	for _, q := range settings.QuietPeriods {
+		normalized, err := normalizeQuietPeriod(q)
+		if err != nil {
+			return DeliverySchedule{}, err
+		}
+		schedule.QuietPeriods = append(schedule.QuietPeriods, normalized)
+	}
+
+	return schedule, nil
+}
+
+// RoomDeliveryScheduleFunc ルームの配信設定を読み込む関数を返す（未設定のルームにはfallbackを使う）
+func RoomDeliveryScheduleFunc(fallback DeliverySchedule) func(roomID string) (DeliverySchedule, error) {
+	return func(roomID string) (DeliverySchedule, error) {
+		settings, err := models.FetchRoomSettings(roomID)
+		if err != nil {
+			return DeliverySchedule{}, err
+		}
+		if settings == nil {
+			return fallback, nil
+		}
+		return ScheduleFromSettings(*settings)
+	}
+}
+
+// normalizeQuietPeriod 開始と終了が同じ形式（時刻または日付）で指定されているか検証し、
+// 時刻は「09:00」のように2桁の時・分にそろえて返す
+func normalizeQuietPeriod(q models.QuietPeriod) (models.QuietPeriod, error) {
+	if start, err1 := time.Parse("15:04", q.Start); err1 == nil {
+		if end, err2 := time.Parse("15:04", q.End); err2 == nil {
+			return models.QuietPeriod{Start: start.Format("15:04"), End: end.Format("15:04")}, nil
+		}
+	}
+	start, err1 := time.Parse("2006-01-02", q.Start)
+	end, err2 := time.Parse("2006-01-02", q.End)
+	if err1 == nil && err2 == nil && !end.Before(start) {
+		return models.QuietPeriod{Start: start.Format("2006-01-02"), End: end.Format("2006-01-02")}, nil
+	}
+	return q, fmt.Errorf("配信停止期間 %s〜%s は「15:04」形式の時刻か「2006-01-02」形式の日付で指定してください", q.Start, q.End)
+}
+
+// quietPeriodContains localが配信停止期間に含まれるかどうか
+func quietPeriodContains(q models.QuietPeriod, local time.Time) bool {
+	// 毎日の時間帯（開始が終了より遅い場合は日をまたぐ）。時刻は0時からの分に直して比べる
+	if start, err := time.Parse("15:04", q.Start); err == nil {
+		end, err := time.Parse("15:04", q.End)
+		if err != nil {
+			return false
+		}
+		now := local.Hour()*60 + local.Minute()
+		from, to := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+		if from <= to {
+			return now >= from && now < to
+		}
+		return now >= from || now < to
+	}
+
+	// 日付の範囲
+	today := local.Format("2006-01-02")
+	return today >= q.Start && today <= q.End
+}
+
+// DeliveryScheduler 予約された記事を各ルームの配信時刻にChatworkへ投稿する
+type DeliveryScheduler struct {
+	client   chatwork.Client
+	schedule func(roomID string) (DeliverySchedule, error)
+	links    *LinkSigner
+	now      func() time.Time
+
+	mu        sync.Mutex
+	lastSlots map[string]time.Time // ルームごとの最後に配信した枠（delivery_slotの内容を覚えておく）
+}
+
+// NewDeliveryScheduler スケジューラを作成
+// scheduleはルームごとの配信スケジュールを返す関数
+// linksを指定した場合は、メッセージの記事URLをクリックを記録する転送用リンクに書き換える
+func NewDeliveryScheduler(client chatwork.Client, schedule func(roomID string) (DeliverySchedule, error), links *LinkSigner) *DeliveryScheduler {
+	return &DeliveryScheduler{
+		client:    client,
+		schedule:  schedule,
+		links:     links,
+		now:       time.Now,
+		lastSlots: make(map[string]time.Time),
+	}
+}
+
+// Run ctxが終了するまで1分ごとに配信処理を行う
+func (s *DeliveryScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(deliverySchedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		s.Tick(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick 全ルームについて、まだ配信していない枠があれば上位の記事を配信し、再試行時刻を過ぎた記事を再送する
+func (s *DeliveryScheduler) Tick(ctx context.Context) {
+	roomIDs, err := models.FetchRoomIDs()
+	if err != nil {
+		fmt.Printf("配信対象ルームの取得エラー: %v\n", err)
+		return
+	}
+
+	for _, roomID := range roomIDs {
+		// ルームごとに時刻を取り直し、前のルームの配信に時間がかかっても遅れた分を枠の判定に反映する
+		now := s.now()
+		schedule, err := s.schedule(roomID)
+		if err != nil {
+			fmt.Printf("配信スケジュールの取得エラー - Room ID: %s, Error: %v\n", roomID, err)
+			continue
+		}
+
+		// 配信停止中・停止期間中は再試行も行わない
+		if !schedule.Enabled || schedule.Quiet(now) {
+			continue
+		}
+
+		limit := 0
+		if slot, ok := s.pendingSlot(roomID, schedule, now); ok {
+			claimed, err := s.claimSlot(roomID, slot)
+			if err != nil {
+				fmt.Printf("配信枠の確保エラー - Room ID: %s, Error: %v\n", roomID, err)
+			} else if claimed {
+				limit = schedule.MaxArticles
+			}
+		}
+
+		if err := s.deliverRoom(ctx, roomID, limit, now); err != nil {
+			fmt.Printf("配信エラー - Room ID: %s, Error: %v\n", roomID, err)
+		}
+	}
+}
+
+// pendingSlot まだ配信していない最新の枠を返す
+// 前回のTickが長引いた場合や再起動した場合も、最後に配信した枠より後の枠は遅れて配信する
+// 最後に配信した枠の記録がないルームは、取りこぼしと区別できないため現在の分の枠だけを対象にする
+func (s *DeliveryScheduler) pendingSlot(roomID string, schedule DeliverySchedule, now time.Time) (time.Time, bool) {
+	after := now.Truncate(time.Minute).Add(-time.Minute)
+
+	s.mu.Lock()
+	last, ok := s.lastSlots[roomID]
+	s.mu.Unlock()
+	if !ok {
+		stored, err := models.FetchDeliverySlot(roomID)
+		if err != nil {
+			fmt.Printf("配信枠の取得エラー - Room ID: %s, Error: %v\n", roomID, err)
+			return time.Time{}, false
+		}
+		if stored != nil {
+			last, ok = *stored, true
+			s.mu.Lock()
+			s.lastSlots[roomID] = last
+			s.mu.Unlock()
+		}
+	}
+	if ok {
+		after = last
+		if limit := now.Add(-deliveryCatchUpWindow); after.Before(limit) {
+			after = limit
+		}
+	}
+	return schedule.LatestSlot(after, now)
+}
+
+// claimSlot 同じ枠で二重に配信しないよう、枠を一度だけ確保する
+// 確保した枠はdelivery_slotに記録し、再起動後や別のインスタンスでは確保できないようにする
+func (s *DeliveryScheduler) claimSlot(roomID string, slot time.Time) (bool, error) {
+	s.mu.Lock()
+	if last, ok := s.lastSlots[roomID]; ok && !slot.After(last) {
+		s.mu.Unlock()
+		return false, nil
+	}
+	s.mu.Unlock()
+
+	claimed, err := models.ClaimDeliverySlot(roomID, slot)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 確保できなかった場合も、他で配信済みの枠として覚えておく
+	if last, ok := s.lastSlots[roomID]; !ok || slot.After(last) {
+		s.lastSlots[roomID] = slot
+	}
+	return claimed, nil
+}
+
+// deliverRoom 再試行待ちの記事のうち時刻を過ぎたものと、配信順で先頭のlimit件の未配信記事を配信する
+func (s *DeliveryScheduler) deliverRoom(ctx context.Context, roomID string, limit int, now time.Time) error {
+	var articles []models.Article
+	var err error
+	if limit > 0 {
+		if articles, err = RescoreRoom(roomID); err != nil {
+			return err
+		}
+		SortArticlesByQueue(articles)
+	} else {
+		// 配信枠でなければ再試行の対象だけを読み込む
+		if articles, err = models.FetchRetryDueArticles(roomID, maxDeliveryAttempts, now); err != nil {
+			return err
+		}
+	}
+
+	var targets []models.Article
+	picked := 0
+	for _, article := range articles {
+		if article.DeliveredAt != nil || article.DeliveryAttempts >= maxDeliveryAttempts {
+			continue
+		}
+		if article.DeliveryAttempts > 0 {
+			// 失敗した記事は再試行時刻を過ぎたら枠とは関係なく再送する
+			if article.NextRetryAt != nil && !article.NextRetryAt.After(now) {
+				targets = append(targets, article)
+			}
+			continue
+		}
+		if picked < limit {
+			targets = append(targets, article)
+			picked++
+		}
+	}
+
+	for _, article := range targets {
+		s.deliverArticle(ctx, article)
+	}
+	return nil
+}
+
+// deliverArticle 記事1件を投稿し、結果を記録する
+func (s *DeliveryScheduler) deliverArticle(ctx context.Context, article models.Article) {
+	reqCtx, cancel := context.WithTimeout(ctx, deliveryRequestTimeout)
+	defer cancel()
+
+	messageID, err := s.client.PostMessage(reqCtx, article.RoomID, s.links.TrackedContent(article))
+	attemptedAt := s.now().UTC()
+
+	log := models.DeliveryLog{
+		RoomID:      article.RoomID,
+		ArticleID:   article.ArticleID,
+		MessageID:   messageID,
+		AttemptedAt: attemptedAt,
+	}
+
+	if err != nil {
+		attempts := article.DeliveryAttempts + 1
+		var nextRetryAt *time.Time
+		if attempts < maxDeliveryAttempts {
+			next := attemptedAt.Add(deliveryRetryBaseDelay << (attempts - 1))
+			nextRetryAt = &next
+		}
+		fmt.Printf("記事ID %d の配信に失敗（%d回目）: %v\n", article.ArticleID, attempts, err)
+
+		if err := models.MarkArticleDeliveryFailed(article.ArticleID, attempts, nextRetryAt, err.Error()); err != nil {
+			fmt.Printf("配信失敗の記録エラー: %v\n", err)
+		}
+		log.Status = models.DeliveryStatusFailed
+		log.Error = err.Error()
+	} else {
+		if err := models.MarkArticleDelivered(article.ArticleID, attemptedAt); err != nil {
+			fmt.Printf("配信済みの記録エラー: %v\n", err)
+		}
+		if err := models.ArchiveArticle(article, models.HistoryEventDelivered, attemptedAt); err != nil {
+			fmt.Printf("配信履歴の保存エラー: %v\n", err)
+		}
+		log.Status = models.DeliveryStatusSucceeded
+	}
+
+	if err := models.RecordDelivery(log); err != nil {
+		fmt.Printf("配信記録の保存エラー: %v\n", err)
+	}
+}
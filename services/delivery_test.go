@@ -0,0 +1,304 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"login-app/chatwork"
+	"login-app/models"
+)
+
+// newTestScheduler FakeServerに投稿するスケジューラを作成する（毎日9:00 UTCに1件配信）
+func newTestScheduler(t *testing.T, now *time.Time) (*DeliveryScheduler, *chatwork.FakeServer) {
+	t.Helper()
+	fake := chatwork.NewFakeServer()
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	cron, err := ParseCron("0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedule := DeliverySchedule{Enabled: true, Location: time.UTC, Crons: []CronSpec{cron}, MaxArticles: 1}
+
+	s := NewDeliveryScheduler(chatwork.NewAPIClient(srv.URL, "test-token"), func(string) (DeliverySchedule, error) {
+		return schedule, nil
+	}, nil)
+	s.now = func() time.Time { return *now }
+	return s, fake
+}
+
+func testArticle(id int64, content string) models.Article {
+	return models.Article{ArticleID: id, RoomID: "1", Content: content, MatchedFields: []string{}}
+}
+
+func TestDeliveryScheduleDue(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cron, _ := ParseCron("0 9 * * 1-5")
+	schedule := DeliverySchedule{Enabled: true, Location: tokyo, Crons: []CronSpec{cron}, MaxArticles: 3}
+
+	// 2026-10-19は月曜日
+	monday := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC) // 9:00 JST
+	if slot, due := schedule.Due(monday); !due || slot != "2026-10-19 09:00" {
+		t.Errorf("Due(月曜9:00) = %q, %v", slot, due)
+	}
+	if _, due := schedule.Due(monday.Add(time.Minute)); due {
+		t.Error("9:01は配信時刻ではない")
+	}
+	if _, due := schedule.Due(monday.AddDate(0, 0, 5)); due {
+		t.Error("土曜日は配信時刻ではない")
+	}
+
+	quiet := schedule
+	quiet.QuietPeriods = []models.QuietPeriod{{Start: "2026-10-19", End: "2026-10-20"}}
+	if _, due := quiet.Due(monday); due {
+		t.Error("配信停止期間中は配信しない")
+	}
+
+	disabled := schedule
+	disabled.Enabled = false
+	if _, due := disabled.Due(monday); due {
+		t.Error("配信停止中は配信しない")
+	}
+}
+
+func TestDeliverySchedulerClaimSlot(t *testing.T) {
+	db := newFakeSupabase(t)
+	slot := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	s := NewDeliveryScheduler(nil, nil, nil)
+	if claimed, err := s.claimSlot("1", slot); err != nil || !claimed {
+		t.Fatalf("最初の確保は成功する: %v, %v", claimed, err)
+	}
+	if claimed, _ := s.claimSlot("1", slot); claimed {
+		t.Error("同じ枠は二度確保できない")
+	}
+	if claimed, _ := s.claimSlot("2", slot); !claimed {
+		t.Error("別のルームは同じ枠を確保できる")
+	}
+	if claimed, _ := s.claimSlot("1", slot.AddDate(0, 0, 1)); !claimed {
+		t.Error("次の枠は確保できる")
+	}
+	if n := len(db.Writes("delivery_slot")); n != 3 {
+		t.Errorf("確保した枠を記録する: %d", n)
+	}
+
+	// 再起動後や別のインスタンスで確保済みの枠は、条件付きの更新・登録がどちらも行を返さない
+	db.Respond(http.MethodPatch, "delivery_slot", []interface{}{})
+	db.Respond(http.MethodPost, "delivery_slot", []interface{}{})
+	other := NewDeliveryScheduler(nil, nil, nil)
+	if claimed, err := other.claimSlot("1", slot); err != nil || claimed {
+		t.Errorf("記録済みの枠は確保できない: %v, %v", claimed, err)
+	}
+}
+
+func TestDeliverySchedulerTickCatchesUpMissedSlot(t *testing.T) {
+	db := newFakeSupabase(t)
+	db.Set("user", []map[string]string{{"room_id": "1"}})
+	db.Set("reserve_article", []models.Article{testArticle(1, "記事1")})
+	db.Set("delivery_slot", []map[string]string{{"slot_at": "2026-10-18T09:00:00Z"}})
+
+	// 9:00のTickに間に合わなかった場合も、前回の枠より後の枠として遅れて配信する
+	now := time.Date(2026, 10, 19, 9, 3, 0, 0, time.UTC)
+	s, fake := newTestScheduler(t, &now)
+	s.Tick(context.Background())
+	if n := len(fake.Messages()); n != 1 {
+		t.Fatalf("取りこぼした枠を配信する: %d件", n)
+	}
+
+	claims := db.Writes("delivery_slot")
+	if len(claims) == 0 || claims[0].Body.(map[string]interface{})["slot_at"] != "2026-10-19T09:00:00Z" {
+		t.Errorf("取りこぼした枠の時刻で確保する: %+v", claims)
+	}
+
+	now = now.Add(time.Minute)
+	s.Tick(context.Background())
+	if n := len(fake.Messages()); n != 1 {
+		t.Errorf("遅れて配信した枠は再び配信しない: %d件", n)
+	}
+}
+
+func TestDeliverySchedulerTickWithoutSlotRecord(t *testing.T) {
+	db := newFakeSupabase(t)
+	db.Set("user", []map[string]string{{"room_id": "1"}})
+	db.Set("reserve_article", []models.Article{testArticle(1, "記事1")})
+
+	// 記録がないルームは、起動前の枠をさかのぼって配信しない
+	now := time.Date(2026, 10, 19, 9, 3, 0, 0, time.UTC)
+	s, fake := newTestScheduler(t, &now)
+	s.Tick(context.Background())
+	if n := len(fake.Messages()); n != 0 {
+		t.Errorf("記録のないルームの過去の枠は配信しない: %d件", n)
+	}
+	if n := len(db.Writes("reserve_article")); n != 0 {
+		t.Errorf("配信枠でなければ採点し直さない: %d", n)
+	}
+}
+
+func TestDeliverySchedulerTickDeliversOncePerSlot(t *testing.T) {
+	db := newFakeSupabase(t)
+	first := 0
+	top := testArticle(1, "[info][title]記事1[/title]https://example.com/1[/info]")
+	top.QueuePosition = &first
+	db.Set("user", []map[string]string{{"room_id": "1"}})
+	db.Set("reserve_article", []models.Article{testArticle(2, "記事2"), top})
+
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	s, fake := newTestScheduler(t, &now)
+
+	s.Tick(context.Background())
+	messages := fake.Messages()
+	if len(messages) != 1 || messages[0].RoomID != "1" || messages[0].Body != top.Content {
+		t.Fatalf("配信順で先頭の記事を1件配信する: %+v", messages)
+	}
+
+	// 同じ枠のTickでは、記事が未配信のままでも二重に配信しない
+	s.Tick(context.Background())
+	now = now.Add(time.Minute)
+	s.Tick(context.Background())
+	if n := len(fake.Messages()); n != 1 {
+		t.Errorf("配信は1回だけ: %d回", n)
+	}
+
+	var delivered, logged, archived bool
+	for _, w := range db.Writes("") {
+		body, _ := w.Body.(map[string]interface{})
+		switch {
+		case w.Method == http.MethodPatch && w.Table == "reserve_article" && w.Query == "article_id=eq.1" && body["delivered_at"] != nil:
+			delivered = true
+		case w.Method == http.MethodPost && w.Table == "delivery_log" && body["status"] == models.DeliveryStatusSucceeded:
+			logged = true
+		case w.Method == http.MethodPost && w.Table == "article_history" && body["delivered_at"] != nil:
+			archived = true
+		}
+	}
+	if !delivered || !logged || !archived {
+		t.Errorf("配信済み・配信記録・履歴を保存する: delivered=%v logged=%v archived=%v", delivered, logged, archived)
+	}
+}
+
+func TestDeliverySchedulerRetryBackoff(t *testing.T) {
+	tests := []struct {
+		attempts  int
+		wantDelay time.Duration // 0の場合は再試行しない
+	}{
+		{0, 5 * time.Minute},
+		{1, 10 * time.Minute},
+		{3, 40 * time.Minute},
+		{maxDeliveryAttempts - 1, 0},
+	}
+	for _, tt := range tests {
+		db := newFakeSupabase(t)
+		now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+		s, fake := newTestScheduler(t, &now)
+		fake.FailNext(1, http.StatusInternalServerError)
+
+		article := testArticle(1, "記事1")
+		article.DeliveryAttempts = tt.attempts
+		s.deliverArticle(context.Background(), article)
+
+		writes := db.Writes("reserve_article")
+		if len(writes) != 1 {
+			t.Fatalf("attempts=%d: 失敗を1回記録する: %+v", tt.attempts, writes)
+		}
+		body := writes[0].Body.(map[string]interface{})
+		if got := body["delivery_attempts"]; got != float64(tt.attempts+1) {
+			t.Errorf("attempts=%d: delivery_attempts = %v", tt.attempts, got)
+		}
+		if tt.wantDelay == 0 {
+			if body["next_retry_at"] != nil {
+				t.Errorf("attempts=%d: 上限に達したら再試行しない: %v", tt.attempts, body["next_retry_at"])
+			}
+			continue
+		}
+		next, err := time.Parse(time.RFC3339, body["next_retry_at"].(string))
+		if err != nil || !next.Equal(now.Add(tt.wantDelay)) {
+			t.Errorf("attempts=%d: next_retry_at = %v, want %v", tt.attempts, body["next_retry_at"], now.Add(tt.wantDelay))
+		}
+
+		logs := db.Writes("delivery_log")
+		if len(logs) != 1 || logs[0].Body.(map[string]interface{})["status"] != models.DeliveryStatusFailed {
+			t.Errorf("attempts=%d: 失敗の配信記録を保存する: %+v", tt.attempts, logs)
+		}
+	}
+}
+
+func TestDeliverySchedulerTickRetriesOutsideSlot(t *testing.T) {
+	db := newFakeSupabase(t)
+	now := time.Date(2026, 10, 19, 10, 30, 0, 0, time.UTC)
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	due := testArticle(1, "再試行する記事")
+	due.DeliveryAttempts, due.NextRetryAt = 1, &past
+	waiting := testArticle(2, "再試行を待つ記事")
+	waiting.DeliveryAttempts, waiting.NextRetryAt = 1, &future
+	exhausted := testArticle(3, "上限に達した記事")
+	exhausted.DeliveryAttempts, exhausted.NextRetryAt = maxDeliveryAttempts, &past
+	fresh := testArticle(4, "未配信の記事")
+
+	db.Set("user", []map[string]string{{"room_id": "1"}})
+	db.Set("reserve_article", []models.Article{due, waiting, exhausted, fresh})
+
+	s, fake := newTestScheduler(t, &now)
+	s.Tick(context.Background())
+
+	messages := fake.Messages()
+	if len(messages) != 1 || messages[0].Body != due.Content {
+		t.Errorf("配信時刻以外は再試行時刻を過ぎた記事だけを再送する: %+v", messages)
+	}
+}
+
+func TestScheduleFromSettingsQuietPeriods(t *testing.T) {
+	settings := models.RoomSettings{
+		DeliveryEnabled: true,
+		TimeZone:        "UTC",
+		DeliveryCron:    []string{"0 * * * *"},
+		MaxArticles:     1,
+		QuietPeriods:    []models.QuietPeriod{{Start: "9:00", End: "17:00"}, {Start: "22:00", End: "7:30"}},
+	}
+	schedule, err := ScheduleFromSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.QuietPeriod{{Start: "09:00", End: "17:00"}, {Start: "22:00", End: "07:30"}}
+	if len(schedule.QuietPeriods) != 2 || schedule.QuietPeriods[0] != want[0] || schedule.QuietPeriods[1] != want[1] {
+		t.Errorf("時刻を2桁にそろえる: %+v", schedule.QuietPeriods)
+	}
+
+	at := func(hour, min int) time.Time { return time.Date(2026, 10, 19, hour, min, 0, 0, time.UTC) }
+	for _, tt := range []struct {
+		t     time.Time
+		quiet bool
+	}{
+		{at(8, 59), false},
+		{at(9, 0), true},
+		{at(16, 59), true},
+		{at(17, 0), false},
+		{at(23, 0), true},
+		{at(7, 29), true},
+		{at(7, 30), false},
+	} {
+		if got := schedule.Quiet(tt.t); got != tt.quiet {
+			t.Errorf("Quiet(%s) = %v, want %v", tt.t.Format("15:04"), got, tt.quiet)
+		}
+	}
+
+	// 正規化前の値が直接渡された場合も時刻として比べる
+	raw := DeliverySchedule{Location: time.UTC, QuietPeriods: []models.QuietPeriod{{Start: "9:00", End: "17:00"}}}
+	if !raw.Quiet(at(12, 0)) {
+		t.Error("「9:00」形式の時間帯も停止期間として扱う")
+	}
+
+	for _, q := range []models.QuietPeriod{{Start: "9", End: "17:00"}, {Start: "09:00", End: "2026-10-20"}, {Start: "2026-10-20", End: "2026-10-19"}} {
+		settings.QuietPeriods = []models.QuietPeriod{q}
+		if _, err := ScheduleFromSettings(settings); err == nil {
+			t.Errorf("%+v はエラーにする", q)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"login-app/models"
+)
+
+// DuplicateGroup 正規化後のURLが一致する記事のまとまり
+type DuplicateGroup struct {
+	URL      string           `json:"url"`
+	KeepID   int64            `json:"keep_id"` // 統合時に残す記事
+	Articles []models.Article `json:"articles"`
+}
+
+// FindDuplicateArticles 正規化後のURLが一致する記事をまとめる（2件以上のまとまりだけを返す）
+func FindDuplicateArticles(articles []models.Article) []DuplicateGroup {
+	byURL := make(map[string][]models.Article)
+	for _, article := range articles {
+		parsed := models.ParseArticleContent(article.Content)
+		normalized, err := models.NormalizeArticleURL(parsed.URL)
+		if err != nil {
+			continue
+		}
+		byURL[normalized] = append(byURL[normalized], article)
+	}
+
+	var groups []DuplicateGroup
+	for u, group := range byURL {
+		if len(group) < 2 {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool { return group[i].ArticleID < group[j].ArticleID })
+		groups = append(groups, DuplicateGroup{URL: u, KeepID: preferredArticle(group).ArticleID, Articles: group})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].URL < groups[j].URL })
+	return groups
+}
+
+// preferredArticle 統合時に残す記事を選ぶ
+// 配信済みの記事を優先し、なければ最も古い記事を残す
+func preferredArticle(group []models.Article) models.Article {
+	for _, article := range group {
+		if article.DeliveredAt != nil {
+			return article
+		}
+	}
+	return group[0]
+}
+
+// MergeDuplicateArticles 重複した記事をkeepIDの記事に統合し、残りを履歴に記録してから削除する
+// タイトル・要約は空であれば他の記事のもので補い、タグは和集合にする
+// 既読・スターはいずれかの記事で付いていれば引き継ぎ、メモはつなげて残す
+func MergeDuplicateArticles(roomID string, keepID int64, mergeIDs []int64) (*models.Article, error) {
+	articles, err := models.FetchArticles(roomID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]models.Article, len(articles))
+	for _, article := range articles {
+		byID[article.ArticleID] = article
+	}
+
+	keep, ok := byID[keepID]
+	if !ok {
+		return nil, fmt.Errorf("記事ID %d が見つかりません", keepID)
+	}
+	keepContent := models.ParseArticleContent(keep.Content)
+	keepURL, err := models.NormalizeArticleURL(keepContent.URL)
+	if err != nil {
+		return nil, fmt.Errorf("記事ID %d のURLが正しくありません", keepID)
+	}
+
+	var merged []models.Article
+	for _, id := range mergeIDs {
+		if id == keepID {
+			continue
+		}
+		article, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("記事ID %d が見つかりません", id)
+		}
+		if u, err := models.NormalizeArticleURL(models.ParseArticleContent(article.Content).URL); err != nil || u != keepURL {
+			return nil, fmt.Errorf("記事ID %d は記事ID %d と同じURLではありません", id, keepID)
+		}
+		merged = append(merged, article)
+	}
+	if len(merged) == 0 {
+		return nil, fmt.Errorf("統合する記事が指定されていません")
+	}
+
+	// 本文の統合（URLは正規化した形にそろえる）
+	if canonical, err := models.CanonicalizeArticleURL(keepContent.URL); err == nil {
+		keepContent.URL = canonical
+	}
+	tags := make(map[string]bool)
+	for _, tag := range keepContent.Tags {
+		tags[tag] = true
+	}
+	for _, article := range merged {
+		content := models.ParseArticleContent(article.Content)
+		if keepContent.Title == "" {
+			keepContent.Title = content.Title
+		}
+		if keepContent.Summary == "" {
+			keepContent.Summary = content.Summary
+		}
+		for _, tag := range content.Tags {
+			if !tags[tag] {
+				tags[tag] = true
+				keepContent.Tags = append(keepContent.Tags, tag)
+			}
+		}
+	}
+	keep.Content = models.RenderArticleContent(keepContent)
+	if err := models.UpdateArticle(keep.ArticleID, map[string]interface{}{"content": keep.Content}); err != nil {
+		return nil, err
+	}
+	if err := RescoreArticle(&keep); err != nil {
+		fmt.Printf("記事の再採点エラー - Article ID: %d, Error: %v\n", keep.ArticleID, err)
+	}
+
+	// 記事状態の統合
+	states, err := models.FetchArticleStates(roomID)
+	if err != nil {
+		return nil, err
+	}
+	keepState := states[keepID]
+	notes := []string{}
+	if keepState.Note != "" {
+		notes = append(notes, keepState.Note)
+	}
+	isRead, isStarred := keepState.IsRead, keepState.IsStarred
+	for _, article := range merged {
+		state := states[article.ArticleID]
+		isRead = isRead || state.IsRead
+		isStarred = isStarred || state.IsStarred
+		if state.Note != "" {
+			notes = append(notes, state.Note)
+		}
+	}
+	note := strings.Join(notes, "\n")
+	if isRead != keepState.IsRead || isStarred != keepState.IsStarred || note != keepState.Note {
+		if err := models.UpdateArticleState(roomID, keepID, map[string]interface{}{
+			"is_read":    isRead,
+			"is_starred": isStarred,
+			"note":       note,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	// 統合した記事は削除済みとして履歴に残してから削除する
+	now := time.Now().UTC()
+	for _, article := range merged {
+		if err := models.ArchiveArticle(article, models.HistoryEventDeleted, now); err != nil {
+			return nil, err
+		}
+		if err := models.DeleteArticle(roomID, article.ArticleID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &keep, nil
+}
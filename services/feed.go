@@ -0,0 +1,494 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"login-app/models"
+
+	"golang.org/x/net/html/charset"
+)
+
+// フィード取得の設定
+const (
+	DefaultFeedPollInterval = 30 * time.Minute
+	feedFetchTimeout        = 30 * time.Second
+	maxFeedSize             = 5 << 20
+	maxFeedSummaryLength    = 400
+)
+
+// ErrNotModified 条件付きGETでフィードが更新されていなかった場合のエラー
+var ErrNotModified = errors.New("フィードは更新されていません")
+
+// FeedItem フィードの記事1件
+type FeedItem struct {
+	Title      string
+	URL        string
+	Summary    string
+	Content    string // タグを除いた本文全体（要約に使う）
+	Categories []string
+	Published  *time.Time
+}
+
+// ParsedFeed フィードを解析した結果
+type ParsedFeed struct {
+	Title string
+	Items []FeedItem
+}
+
+// RSS 2.0 と RSS 1.0（RDF）の要素
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        string   `xml:"guid"`
+	Description string   `xml:"description"`
+	Encoded     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate     string   `xml:"pubDate"`
+	DCDate      string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Categories  []string `xml:"category"`
+	Subjects    []string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+}
+
+type rssDocument struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	// RSS 1.0ではitemがchannelと同じ階層にある
+	Items []rssItem `xml:"item"`
+}
+
+// Atom 1.0 の要素
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+type atomEntry struct {
+	Title      string     `xml:"title"`
+	Links      []atomLink `xml:"link"`
+	Summary    string     `xml:"summary"`
+	Content    string     `xml:"content"`
+	Published  string     `xml:"published"`
+	Updated    string     `xml:"updated"`
+	Categories []struct {
+		Term string `xml:"term,attr"`
+	} `xml:"category"`
+}
+
+type atomDocument struct {
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// ParseFeed RSS 2.0、RSS 1.0、Atom 1.0 のフィードを解析する
+func ParseFeed(data []byte) (*ParsedFeed, error) {
+	root, err := feedRootElement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch root {
+	case "rss", "RDF":
+		var doc rssDocument
+		if err := decodeFeedXML(data, &doc); err != nil {
+			return nil, err
+		}
+		items := doc.Channel.Items
+		if len(items) == 0 {
+			items = doc.Items
+		}
+
+		feed := &ParsedFeed{Title: strings.TrimSpace(doc.Channel.Title)}
+		for _, item := range items {
+			link := strings.TrimSpace(item.Link)
+			if link == "" && strings.HasPrefix(item.GUID, "http") {
+				link = strings.TrimSpace(item.GUID)
+			}
+			summary := item.Description
+			if summary == "" {
+				summary = item.Encoded
+			}
+			body := item.Encoded
+			if body == "" {
+				body = item.Description
+			}
+			feed.Items = append(feed.Items, FeedItem{
+				Title:      strings.TrimSpace(HTMLToText(item.Title)),
+				URL:        link,
+				Summary:    truncateRunes(HTMLToText(summary), maxFeedSummaryLength),
+				Content:    HTMLToText(body),
+				Categories: append(trimAll(item.Categories), trimAll(item.Subjects)...),
+				Published:  parseFeedTime(item.PubDate, item.DCDate),
+			})
+		}
+		return feed, nil
+
+	case "feed":
+		var doc atomDocument
+		if err := decodeFeedXML(data, &doc); err != nil {
+			return nil, err
+		}
+
+		feed := &ParsedFeed{Title: strings.TrimSpace(doc.Title)}
+		for _, entry := range doc.Entries {
+			summary := entry.Summary
+			if summary == "" {
+				summary = entry.Content
+			}
+			body := entry.Content
+			if body == "" {
+				body = entry.Summary
+			}
+			var categories []string
+			for _, c := range entry.Categories {
+				categories = append(categories, c.Term)
+			}
+			feed.Items = append(feed.Items, FeedItem{
+				Title:      strings.TrimSpace(HTMLToText(entry.Title)),
+				URL:        atomEntryLink(entry.Links),
+				Summary:    truncateRunes(HTMLToText(summary), maxFeedSummaryLength),
+				Content:    HTMLToText(body),
+				Categories: trimAll(categories),
+				Published:  parseFeedTime(entry.Published, entry.Updated),
+			})
+		}
+		return feed, nil
+	}
+
+	return nil, fmt.Errorf("RSSまたはAtomのフィードではありません（ルート要素: %s）", root)
+}
+
+// FetchFeed 条件付きGET（If-None-Match・If-Modified-Since）でフィードを取得する
+// 更新がない場合はErrNotModifiedを返す。取得に成功した場合はfeedのETag・LastModifiedを更新する
+// フィードのURLは利用者が登録するため、clientにはNewSafeHTTPClientで作成した内部ネットワークに接続しないクライアントを渡す
+func FetchFeed(ctx context.Context, client *http.Client, feed *models.Feed) (*ParsedFeed, error) {
+	ctx, cancel := context.WithTimeout(ctx, feedFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", feed.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("リクエストの作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+	if feed.ETag != "" {
+		req.Header.Set("If-None-Match", feed.ETag)
+	}
+	if feed.LastModified != "" {
+		req.Header.Set("If-Modified-Since", feed.LastModified)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("フィードの取得に失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, ErrNotModified
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("フィードの取得に失敗しました（Status: %d）", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("フィードの読み込みに失敗しました: %w", err)
+	}
+	if len(data) > maxFeedSize {
+		return nil, fmt.Errorf("フィードのサイズが大きすぎます")
+	}
+
+	parsed, err := ParseFeed(data)
+	if err != nil {
+		return nil, err
+	}
+
+	feed.ETag = resp.Header.Get("ETag")
+	feed.LastModified = resp.Header.Get("Last-Modified")
+	if feed.Title == "" {
+		feed.Title = parsed.Title
+	}
+	return parsed, nil
+}
+
+// FeedPoller 登録されたフィードを定期的に取得して記事を取り込む
+type FeedPoller struct {
+	client        *http.Client
+	canonicalizer *URLCanonicalizer
+	summarizer    *ArticleSummarizer
+	interval      time.Duration
+}
+
+// NewFeedPoller ポーラーを作成（intervalが0以下の場合は既定の間隔を使う）
+// summarizerを指定した場合、本文が長い記事は要約してから取り込む
+// clientにはNewSafeHTTPClientで作成した内部ネットワークに接続しないクライアントを渡す
+func NewFeedPoller(client *http.Client, summarizer *ArticleSummarizer, interval time.Duration) *FeedPoller {
+	if interval <= 0 {
+		interval = DefaultFeedPollInterval
+	}
+	return &FeedPoller{
+		client:        client,
+		canonicalizer: NewURLCanonicalizer(client),
+		summarizer:    summarizer,
+		interval:      interval,
+	}
+}
+
+// FeedPollInterval 環境変数FEED_POLL_INTERVAL（"30m" などの形式）から取得間隔を読み込む
+func FeedPollInterval() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("FEED_POLL_INTERVAL")); err == nil && d > 0 {
+		return d
+	}
+	return DefaultFeedPollInterval
+}
+
+// Fetch ポーラーのHTTPクライアントでフィードを取得する
+func (p *FeedPoller) Fetch(ctx context.Context, feed *models.Feed) (*ParsedFeed, error) {
+	return FetchFeed(ctx, p.client, feed)
+}
+
+// Run ctxが終了するまで一定間隔ですべてのフィードを取得する
+func (p *FeedPoller) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.PollAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PollAll すべてのフィードを1回ずつ取得する
+func (p *FeedPoller) PollAll(ctx context.Context) {
+	feeds, err := models.FetchAllFeeds()
+	if err != nil {
+		fmt.Printf("フィード一覧の取得エラー: %v\n", err)
+		return
+	}
+
+	for i := range feeds {
+		if ctx.Err() != nil {
+			return
+		}
+		added, err := p.Poll(ctx, &feeds[i])
+		if err != nil {
+			fmt.Printf("フィードの取り込みエラー - URL: %s, Error: %v\n", feeds[i].URL, err)
+		} else if added > 0 {
+			fmt.Printf("フィード %s から%d件の記事を取り込みました\n", feeds[i].URL, added)
+		}
+	}
+}
+
+// Poll フィードを取得し、未登録のURLの記事をreserve_articleに追加して追加件数を返す
+func (p *FeedPoller) Poll(ctx context.Context, feed *models.Feed) (int, error) {
+	parsed, fetchErr := FetchFeed(ctx, p.client, feed)
+
+	now := time.Now().UTC()
+	feed.LastFetchedAt = &now
+	feed.LastError = ""
+	if fetchErr != nil && fetchErr != ErrNotModified {
+		feed.LastError = fetchErr.Error()
+	}
+	if err := models.UpdateFeedFetchState(*feed); err != nil {
+		fmt.Printf("フィードの取得状態の保存エラー: %v\n", err)
+	}
+
+	if fetchErr == ErrNotModified {
+		return 0, nil
+	}
+	if fetchErr != nil {
+		return 0, fetchErr
+	}
+
+	return p.Ingest(ctx, feed.RoomID, feed.Title, parsed.Items)
+}
+
+// Ingest フィードの記事をルームに取り込む
+// URLは正規化して保存し、予約中・履歴にある記事と正規化後のURLが一致するものは取り込まない
+func (p *FeedPoller) Ingest(ctx context.Context, roomID, feedTitle string, items []FeedItem) (int, error) {
+	known, err := knownArticleURLs(roomID)
+	if err != nil {
+		return 0, err
+	}
+	fields, err := models.FetchFields(roomID)
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, item := range items {
+		// 記法を差し込むようなURLは正規化で弾かれる
+		canonical, err := p.canonicalizer.Canonicalize(ctx, item.URL)
+		if err != nil {
+			fmt.Printf("フィードの記事のURLを取り込めません - URL: %q, Error: %v\n", item.URL, err)
+			continue
+		}
+		normalized, err := models.NormalizeArticleURL(canonical)
+		if err != nil || known[normalized] {
+			continue
+		}
+		known[normalized] = true
+
+		content := models.ArticleContent{
+			Title:   item.Title,
+			URL:     canonical,
+			Summary: item.Summary,
+			Tags:    item.Categories,
+		}
+		if p.summarizer != nil && len([]rune(item.Content)) > maxFeedSummaryLength {
+			if summary, err := p.summarizer.SummarizeText(ctx, item.Title, item.Content); err == nil {
+				content.Summary = summary
+			} else {
+				fmt.Printf("要約の作成エラー - URL: %s, Error: %v\n", canonical, err)
+			}
+		}
+
+		// 分野には最も一致した登録ワードを、なければフィード名を使う
+		if result := ScoreArticle(content, fields); len(result.MatchedFields) > 0 {
+			content.Field = result.MatchedFields[0]
+		} else {
+			content.Field = feedTitle
+		}
+
+		created, err := models.CreateArticle(roomID, models.RenderArticleContent(content))
+		if err != nil {
+			return added, err
+		}
+		if err := saveArticleScore(created, fields); err != nil {
+			fmt.Printf("記事の採点エラー - Article ID: %d, Error: %v\n", created.ArticleID, err)
+		}
+		added++
+	}
+	return added, nil
+}
+
+// knownArticleURLs ルームの予約中・履歴の記事の正規化済みURL
+func knownArticleURLs(roomID string) (map[string]bool, error) {
+	articles, err := models.FetchArticles(roomID)
+	if err != nil {
+		return nil, err
+	}
+	historyURLs, err := models.FetchHistoryURLs(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(articles)+len(historyURLs))
+	add := func(raw string) {
+		if normalized, err := models.NormalizeArticleURL(raw); err == nil {
+			known[normalized] = true
+		}
+	}
+	for _, article := range articles {
+		add(models.ParseArticleContent(article.Content).URL)
+	}
+	for _, u := range historyURLs {
+		add(u)
+	}
+	return known, nil
+}
+
+// feedRootElement XMLのルート要素名を返す
+func feedRootElement(data []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("XMLの解析に失敗しました: %w", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+// decodeFeedXML 文字コードを考慮してXMLをデコードする
+func decodeFeedXML(data []byte, v interface{}) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("XMLの解析に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// atomEntryLink rel="alternate"（または未指定）のリンクを返す
+func atomEntryLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+	if len(links) > 0 {
+		return strings.TrimSpace(links[0].Href)
+	}
+	return ""
+}
+
+// parseFeedTime RSS・Atomで使われる日時形式を順に試す
+func parseFeedTime(values ...string) *time.Time {
+	layouts := []string{time.RFC1123Z, time.RFC1123, time.RFC3339, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST", "2006-01-02"}
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return &t
+			}
+		}
+	}
+	return nil
+}
+
+func trimAll(values []string) []string {
+	var result []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+var (
+	htmlTagRegex   = regexp.MustCompile(`(?s)<[^>]*>`)
+	htmlBlockRegex = regexp.MustCompile(`(?is)<(script|style)[^>]*>.*?</(script|style)>`)
+)
+
+// HTMLToText HTMLのタグを取り除き、文字参照を戻して空白をまとめる
+// 文字参照を戻して現れたタグ（"&lt;img ...&gt;" など）も取り除き、タグを含む文字列を返さない
+func HTMLToText(s string) string {
+	return stripHTMLTags(html.UnescapeString(stripHTMLTags(s)))
+}
+
+// stripHTMLTags 文字参照を展開済みの文字列からタグを取り除き、空白をまとめる
+func stripHTMLTags(s string) string {
+	s = htmlBlockRegex.ReplaceAllString(s, " ")
+	s = htmlTagRegex.ReplaceAllString(s, " ")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// truncateRunes 文字数がmaxを超える場合は切り詰めて "…" を付ける
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:max])) + "…"
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"login-app/models"
+)
+
+const testRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<channel>
+  <title>テストブログ</title>
+  <item>
+    <title>Go &amp; テスト</title>
+    <link>https://example.com/posts/1</link>
+    <description>&lt;p&gt;最初の&lt;b&gt;記事&lt;/b&gt;です。&lt;/p&gt;</description>
+    <content:encoded><![CDATA[<p>本文<script>alert(1)</script>全体</p>]]></content:encoded>
+    <category>Go</category>
+    <category> テスト </category>
+    <pubDate>Mon, 19 Oct 2026 09:00:00 +0900</pubDate>
+  </item>
+  <item>
+    <title>リンクのない記事</title>
+    <guid>https://example.com/posts/2</guid>
+  </item>
+</channel>
+</rss>`
+
+const testRDF = `<?xml version="1.0" encoding="UTF-8"?>
+<rdf:RDF xmlns="http://purl.org/rss/1.0/" xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel><title>RDFのフィード</title></channel>
+  <item>
+    <title>RDFの記事</title>
+    <link>https://example.com/rdf/1</link>
+    <description>概要</description>
+    <dc:date>2026-10-19T09:00:00+09:00</dc:date>
+    <dc:subject>ニュース</dc:subject>
+  </item>
+</rdf:RDF>`
+
+const testAtom = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Atomのフィード</title>
+  <entry>
+    <title>Atomの記事</title>
+    <link rel="self" href="https://example.com/atom/1.xml"/>
+    <link rel="alternate" href=" https://example.com/atom/1 "/>
+    <content type="html">&lt;img src=x onerror=alert(1)&gt;本文</content>
+    <updated>2026-10-19T00:00:00Z</updated>
+    <category term="セキュリティ"/>
+  </entry>
+</feed>`
+
+func TestParseFeedRSS(t *testing.T) {
+	feed, err := ParseFeed([]byte(testRSS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "テストブログ" || len(feed.Items) != 2 {
+		t.Fatalf("feed = %+v", feed)
+	}
+
+	item := feed.Items[0]
+	if item.Title != "Go & テスト" || item.URL != "https://example.com/posts/1" {
+		t.Errorf("title, url = %q, %q", item.Title, item.URL)
+	}
+	if item.Summary != "最初の 記事 です。" {
+		t.Errorf("summary = %q", item.Summary)
+	}
+	if item.Content != "本文 全体" {
+		t.Errorf("content = %q", item.Content)
+	}
+	if strings.Join(item.Categories, ",") != "Go,テスト" {
+		t.Errorf("categories = %q", item.Categories)
+	}
+	if item.Published == nil || !item.Published.Equal(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("published = %v", item.Published)
+	}
+
+	if feed.Items[1].URL != "https://example.com/posts/2" {
+		t.Errorf("linkがない場合はguidを使う: %q", feed.Items[1].URL)
+	}
+}
+
+func TestParseFeedRDF(t *testing.T) {
+	feed, err := ParseFeed([]byte(testRDF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "RDFのフィード" || len(feed.Items) != 1 {
+		t.Fatalf("feed = %+v", feed)
+	}
+	item := feed.Items[0]
+	if item.URL != "https://example.com/rdf/1" || item.Summary != "概要" {
+		t.Errorf("item = %+v", item)
+	}
+	if strings.Join(item.Categories, ",") != "ニュース" {
+		t.Errorf("categories = %q", item.Categories)
+	}
+	if item.Published == nil || !item.Published.Equal(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("published = %v", item.Published)
+	}
+}
+
+func TestParseFeedAtom(t *testing.T) {
+	feed, err := ParseFeed([]byte(testAtom))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "Atomのフィード" || len(feed.Items) != 1 {
+		t.Fatalf("feed = %+v", feed)
+	}
+	item := feed.Items[0]
+	if item.URL != "https://example.com/atom/1" {
+		t.Errorf("rel=alternateのリンクを使う: %q", item.URL)
+	}
+	// 文字参照で書かれたタグも取り除く
+	if item.Summary != "本文" || item.Content != "本文" {
+		t.Errorf("summary, content = %q, %q", item.Summary, item.Content)
+	}
+	if strings.Join(item.Categories, ",") != "セキュリティ" {
+		t.Errorf("categories = %q", item.Categories)
+	}
+	if item.Published == nil || !item.Published.Equal(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("publishedがない場合はupdatedを使う: %v", item.Published)
+	}
+}
+
+func TestParseFeedRejectsOtherXML(t *testing.T) {
+	if _, err := ParseFeed([]byte(`<html><body>not a feed</body></html>`)); err == nil {
+		t.Error("RSS・Atom以外はエラーにする")
+	}
+	if _, err := ParseFeed([]byte(`not xml`)); err == nil {
+		t.Error("XMLでなければエラーにする")
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	tests := map[string]string{
+		"<p>a</p><p>b</p>":                       "a b",
+		"&lt;script&gt;alert(1)&lt;/script&gt;x": "x",
+		"1 &lt; 2":                               "1 < 2",
+		"&amp;lt;b&amp;gt;":                      "&lt;b&gt;",
+	}
+	for in, want := range tests {
+		if got := HTMLToText(in); got != want {
+			t.Errorf("HTMLToText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFetchFeedConditionalGet(t *testing.T) {
+	const etag = `"v1"`
+	const lastModified = "Mon, 19 Oct 2026 00:00:00 GMT"
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == etag && r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(testRSS))
+	}))
+	defer srv.Close()
+
+	feed := &models.Feed{RoomID: "1", URL: srv.URL}
+	parsed, err := FetchFeed(context.Background(), srv.Client(), feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.Items) != 2 {
+		t.Errorf("items = %d", len(parsed.Items))
+	}
+	if feed.ETag != etag || feed.LastModified != lastModified || feed.Title != "テストブログ" {
+		t.Errorf("ETag・Last-Modified・タイトルを保存する: %+v", feed)
+	}
+
+	if _, err := FetchFeed(context.Background(), srv.Client(), feed); err != ErrNotModified {
+		t.Errorf("更新がなければErrNotModified: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d", requests)
+	}
+}
+
+func TestFetchFeedErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusGone)
+	}))
+	defer srv.Close()
+
+	feed := &models.Feed{RoomID: "1", URL: srv.URL, ETag: `"old"`}
+	if _, err := FetchFeed(context.Background(), srv.Client(), feed); err == nil || err == ErrNotModified {
+		t.Errorf("200以外はエラーにする: %v", err)
+	}
+	if feed.ETag != `"old"` {
+		t.Errorf("失敗した場合はETagを変えない: %q", feed.ETag)
+	}
+}
+
+func TestFeedPollerPollNotModified(t *testing.T) {
+	db := newFakeSupabase(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer srv.Close()
+
+	p := NewFeedPoller(srv.Client(), nil, 0)
+	feed := &models.Feed{ID: 1, RoomID: "1", URL: srv.URL, ETag: `"v1"`}
+	added, err := p.Poll(context.Background(), feed)
+	if err != nil || added != 0 {
+		t.Fatalf("Poll = %d, %v", added, err)
+	}
+	if feed.LastFetchedAt == nil || feed.LastError != "" {
+		t.Errorf("取得状態を更新する: %+v", feed)
+	}
+	if n := len(db.Writes("feed")); n != 1 {
+		t.Errorf("取得状態を1回保存する: %d", n)
+	}
+	if n := len(db.Writes("reserve_article")); n != 0 {
+		t.Errorf("更新がなければ記事を取り込まない: %d", n)
+	}
+}
+
+func TestFeedPollerIngestDedup(t *testing.T) {
+	db := newFakeSupabase(t)
+	db.Set("reserve_article", []models.Article{
+		testArticle(1, models.RenderArticleContent(models.ArticleContent{Title: "予約中", URL: "https://example.com/reserved"})),
+	})
+	db.Set("article_history", []map[string]string{{"url": "https://www.example.com/delivered/"}})
+
+	items := []FeedItem{
+		{Title: "予約中の記事", URL: "http://example.com/reserved?utm_source=feed"},
+		{Title: "配信済みの記事", URL: "https://example.com/delivered"},
+		{Title: "新しい記事", URL: "https://example.com/new?b=2&a=1"},
+		{Title: "同じフィード内の重複", URL: "https://www.example.com/new/?a=1&b=2"},
+		{Title: "記法を含むURL", URL: "https://example.com/x[/info]"},
+		{Title: "URLなし"},
+	}
+
+	p := NewFeedPoller(http.DefaultClient, nil, 0)
+	added, err := p.Ingest(context.Background(), "1", "テストブログ", items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 1 {
+		t.Errorf("added = %d", added)
+	}
+
+	var created, scored []fakeWrite
+	for _, w := range db.Writes("reserve_article") {
+		switch w.Method {
+		case http.MethodPost:
+			created = append(created, w)
+		case http.MethodPatch:
+			scored = append(scored, w)
+		}
+	}
+	if len(created) != 1 {
+		t.Fatalf("新しい記事だけを登録する: %+v", created)
+	}
+	body := created[0].Body.(map[string]interface{})
+	content := models.ParseArticleContent(body["content"].(string))
+	if content.Title != "新しい記事" || content.URL != "https://example.com/new?b=2&a=1" || content.Field != "テストブログ" {
+		t.Errorf("content = %+v", content)
+	}
+	if len(scored) != 1 || scored[0].Body.(map[string]interface{})["scored_at"] == nil {
+		t.Errorf("登録した記事を採点して保存する: %+v", scored)
+	}
+}
+
+func TestFetchFeedSafeClientRejectsLoopback(t *testing.T) {
+	t.Setenv("ALLOW_PRIVATE_FETCH", "")
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(testRSS))
+	}))
+	defer srv.Close()
+
+	feed := &models.Feed{RoomID: "1", URL: srv.URL}
+	if _, err := FetchFeed(context.Background(), NewSafeHTTPClient(time.Second), feed); !errors.Is(err, ErrForbiddenAddress) {
+		t.Errorf("内部ネットワークのフィードは取得しない: %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("requests = %d", requests)
+	}
+}
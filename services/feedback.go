@@ -0,0 +1,341 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"login-app/models"
+)
+
+// 反応による優先度の調整の設定
+const (
+	feedbackWindow       = 30 * 24 * time.Hour // 集計する反応の期間
+	clickFeedbackWeight  = 0.5                 // クリックは👍の半分の重みで数える
+	minTuningSignals     = 5                   // これより反応が少ない分野は変更しない
+	raiseEngagementRate  = 0.3                 // 反応率がこれ以上なら優先度を上げる
+	lowerEngagementRate  = -0.1                // 反応率がこれ以下なら優先度を下げる
+	tuningCooldown       = 14 * 24 * time.Hour // 反応をもとに変更した分野はこの期間は変更しない
+	fieldTuningInterval  = 7 * 24 * time.Hour  // 自動反映の間隔
+	fieldTunerCheckEvery = time.Hour
+	maxPriorityChanges   = 20 // 調整結果に含める変更履歴の件数
+)
+
+// FieldFeedback 分野ごとの反応の集計
+type FieldFeedback struct {
+	FieldName  string  `json:"field_name"`
+	Priority   int     `json:"priority"`
+	Articles   int     `json:"articles"` // 期間内に一致した記事の件数
+	Up         int     `json:"up"`
+	Down       int     `json:"down"`
+	Clicks     int     `json:"clicks"` // クリックされた記事の件数
+	Engagement float64 `json:"engagement"`
+}
+
+// PriorityProposal 分野の優先度の変更案
+type PriorityProposal struct {
+	FieldName string `json:"field_name"`
+	Current   int    `json:"current"`
+	Proposed  int    `json:"proposed"`
+	Reason    string `json:"reason"`
+}
+
+// FieldTuningReport ルームの反応の集計と優先度の変更案
+type FieldTuningReport struct {
+	Mode       string                  `json:"mode"`
+	LastRunAt  *time.Time              `json:"last_run_at"`
+	WindowDays int                     `json:"window_days"`
+	Fields     []FieldFeedback         `json:"fields"`
+	Proposals  []PriorityProposal      `json:"proposals"`
+	Changes    []models.PriorityChange `json:"changes"`
+}
+
+// AggregateFeedback 反応を分野ごとに集計する
+// 👍・👎は記事ごとに最後の評価だけを数え、クリックは同じ記事を何度開いても1件として数える
+// 反応率は「(👍 − 👎 + クリック × 0.5) ÷ 一致した記事の件数」
+func AggregateFeedback(fields []models.Field, matches []models.ArticleMatch, feedback []models.ArticleFeedback) []FieldFeedback {
+	type articleSignal struct {
+		vote    string
+		clicked bool
+		fields  []string
+	}
+	signals := make(map[int64]*articleSignal)
+	for _, f := range feedback {
+		s, ok := signals[f.ArticleID]
+		if !ok {
+			s = &articleSignal{}
+			signals[f.ArticleID] = s
+		}
+		switch f.Signal {
+		case models.FeedbackUp, models.FeedbackDown:
+			s.vote = f.Signal
+		case models.FeedbackClear:
+			s.vote = ""
+		case models.FeedbackClick:
+			s.clicked = true
+		}
+		// 一致した分野は最後の反応の時点のものを使う
+		s.fields = f.MatchedFields
+	}
+
+	stats := make(map[string]*FieldFeedback, len(fields))
+	result := make([]FieldFeedback, len(fields))
+	for i, field := range fields {
+		result[i] = FieldFeedback{FieldName: field.FieldName, Priority: field.Priority}
+		stats[field.FieldName] = &result[i]
+	}
+
+	// 一致した記事の件数（反応した記事も含めて重複なく数える）
+	seen := make(map[string]map[int64]bool)
+	countArticle := func(name string, articleID int64) {
+		stat, ok := stats[name]
+		if !ok {
+			return
+		}
+		if seen[name] == nil {
+			seen[name] = make(map[int64]bool)
+		}
+		if !seen[name][articleID] {
+			seen[name][articleID] = true
+			stat.Articles++
+		}
+	}
+	for _, m := range matches {
+		for _, name := range m.MatchedFields {
+			countArticle(name, m.ArticleID)
+		}
+	}
+
+	for articleID, s := range signals {
+		for _, name := range s.fields {
+			stat, ok := stats[name]
+			if !ok {
+				continue
+			}
+			countArticle(name, articleID)
+			switch s.vote {
+			case models.FeedbackUp:
+				stat.Up++
+			case models.FeedbackDown:
+				stat.Down++
+			}
+			if s.clicked {
+				stat.Clicks++
+			}
+		}
+	}
+
+	for i := range result {
+		if result[i].Articles > 0 {
+			rate := (float64(result[i].Up-result[i].Down) + clickFeedbackWeight*float64(result[i].Clicks)) / float64(result[i].Articles)
+			result[i].Engagement = math.Round(rate*100) / 100
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Engagement > result[j].Engagement })
+	return result
+}
+
+// ProposePriorityChanges 反応の集計から優先度の変更案を作る
+// 反応が十分にある分野のうち、反応率が高いものは1上げ、低いものは1下げる（1〜5の範囲内）
+// frozenに含まれる分野は最近変更したため対象にしない
+func ProposePriorityChanges(stats []FieldFeedback, frozen map[string]bool) []PriorityProposal {
+	var proposals []PriorityProposal
+	for _, s := range stats {
+		if frozen[s.FieldName] || s.Up+s.Down+s.Clicks < minTuningSignals {
+			continue
+		}
+
+		proposed := s.Priority
+		var direction string
+		switch {
+		case s.Engagement >= raiseEngagementRate && s.Priority < models.MaxPriority:
+			proposed, direction = s.Priority+1, "反応が良いため"
+		case s.Engagement <= lowerEngagementRate && s.Priority > models.MinPriority:
+			proposed, direction = s.Priority-1, "評価が低いため"
+		default:
+			continue
+		}
+
+		proposals = append(proposals, PriorityProposal{
+			FieldName: s.FieldName,
+			Current:   s.Priority,
+			Proposed:  proposed,
+			Reason: fmt.Sprintf("直近%d日で一致した記事%d件に👍%d件・👎%d件・クリック%d件（反応率%.2f）。%s優先度を%dから%dに変更します",
+				int(feedbackWindow/(24*time.Hour)), s.Articles, s.Up, s.Down, s.Clicks, s.Engagement, direction, s.Priority, proposed),
+		})
+	}
+	return proposals
+}
+
+// BuildFieldTuningReport ルームの反応を集計し、自動調整のモードがoff以外であれば優先度の変更案を作る
+func BuildFieldTuningReport(roomID string, now time.Time) (*FieldTuningReport, error) {
+	tuning, err := models.FetchFieldTuning(roomID)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := models.FetchFields(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	since := now.Add(-feedbackWindow)
+	feedback, err := models.FetchArticleFeedback(roomID, since)
+	if err != nil {
+		return nil, err
+	}
+	// 一致した記事は予約中の記事と期間内に配信・削除された記事から数える
+	matches, err := models.FetchHistoryMatches(roomID, since)
+	if err != nil {
+		return nil, err
+	}
+	articles, err := models.FetchArticles(roomID)
+	if err != nil {
+		return nil, err
+	}
+	for _, article := range articles {
+		matches = append(matches, models.ArticleMatch{ArticleID: article.ArticleID, MatchedFields: article.MatchedFields})
+	}
+
+	changes, err := models.FetchPriorityChanges(roomID, maxPriorityChanges)
+	if err != nil {
+		return nil, err
+	}
+	frozen := make(map[string]bool)
+	for _, change := range changes {
+		if change.CreatedAt != nil && now.Sub(*change.CreatedAt) < tuningCooldown {
+			frozen[change.FieldName] = true
+		}
+	}
+
+	report := &FieldTuningReport{
+		Mode:       tuning.Mode,
+		LastRunAt:  tuning.LastRunAt,
+		WindowDays: int(feedbackWindow / (24 * time.Hour)),
+		Fields:     AggregateFeedback(fields, matches, feedback),
+		Proposals:  []PriorityProposal{},
+		Changes:    changes,
+	}
+	if tuning.Mode != models.TuningModeOff {
+		if proposals := ProposePriorityChanges(report.Fields, frozen); proposals != nil {
+			report.Proposals = proposals
+		}
+	}
+	if report.Changes == nil {
+		report.Changes = []models.PriorityChange{}
+	}
+	return report, nil
+}
+
+// ApplyPriorityProposals 変更案をルームの分野に反映し、変更を記録する
+// 変更案を作った後に優先度が変わった分野や削除された分野は反映しない
+func ApplyPriorityProposals(roomID string, proposals []PriorityProposal, automatic bool) ([]models.PriorityChange, error) {
+	fields, err := models.FetchFields(roomID)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int, len(fields))
+	for i, field := range fields {
+		index[field.FieldName] = i
+	}
+
+	var changes []models.PriorityChange
+	for _, p := range proposals {
+		i, ok := index[p.FieldName]
+		if !ok || fields[i].Priority != p.Current || !models.ValidPriority(p.Proposed) {
+			continue
+		}
+		if err := models.UpdateFieldPriority(roomID, p.FieldName, p.Proposed); err != nil {
+			// 反映済みの変更は記録してから失敗を返す
+			if recordErr := models.RecordPriorityChanges(changes); recordErr != nil {
+				fmt.Printf("優先度の変更の記録エラー: %v\n", recordErr)
+			}
+			rescoreAfterPriorityChanges(roomID, changes)
+			return nil, err
+		}
+		changes = append(changes, models.PriorityChange{
+			RoomID:      roomID,
+			FieldName:   p.FieldName,
+			OldPriority: p.Current,
+			NewPriority: p.Proposed,
+			Reason:      p.Reason,
+			Automatic:   automatic,
+		})
+	}
+
+	rescoreAfterPriorityChanges(roomID, changes)
+	if err := models.RecordPriorityChanges(changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// rescoreAfterPriorityChanges 優先度を変えた場合はルームの記事を採点し直す
+func rescoreAfterPriorityChanges(roomID string, changes []models.PriorityChange) {
+	if len(changes) == 0 {
+		return
+	}
+	if _, err := RescoreRoom(roomID); err != nil {
+		fmt.Printf("記事の再採点エラー - Room ID: %s, Error: %v\n", roomID, err)
+	}
+}
+
+// FieldTuner 自動調整のモードがapplyのルームについて、優先度の変更案を定期的に反映する
+type FieldTuner struct {
+	now func() time.Time
+}
+
+// NewFieldTuner 自動調整の処理を作成
+func NewFieldTuner() *FieldTuner {
+	return &FieldTuner{now: time.Now}
+}
+
+// Run ctxが終了するまで1時間ごとに、前回の反映から7日以上たったルームを調整する
+func (t *FieldTuner) Run(ctx context.Context) {
+	ticker := time.NewTicker(fieldTunerCheckEvery)
+	defer ticker.Stop()
+
+	for {
+		t.Tick()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick 反映の時期になったルームの優先度を調整する
+func (t *FieldTuner) Tick() {
+	tunings, err := models.FetchFieldTuningsByMode(models.TuningModeApply)
+	if err != nil {
+		fmt.Printf("自動調整の設定の取得エラー: %v\n", err)
+		return
+	}
+
+	now := t.now().UTC()
+	for _, tuning := range tunings {
+		if tuning.LastRunAt != nil && now.Sub(*tuning.LastRunAt) < fieldTuningInterval {
+			continue
+		}
+
+		report, err := BuildFieldTuningReport(tuning.RoomID, now)
+		if err != nil {
+			fmt.Printf("反応の集計エラー - Room ID: %s, Error: %v\n", tuning.RoomID, err)
+			continue
+		}
+		changes, err := ApplyPriorityProposals(tuning.RoomID, report.Proposals, true)
+		if err != nil {
+			fmt.Printf("優先度の自動調整エラー - Room ID: %s, Error: %v\n", tuning.RoomID, err)
+			continue
+		}
+		if err := models.MarkFieldTuningRun(tuning.RoomID, now); err != nil {
+			fmt.Printf("自動調整の実行時刻の記録エラー - Room ID: %s, Error: %v\n", tuning.RoomID, err)
+		}
+		for _, change := range changes {
+			fmt.Printf("優先度を自動調整しました - Room ID: %s, Field: %s, %d → %d\n",
+				change.RoomID, change.FieldName, change.OldPriority, change.NewPriority)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"login-app/models"
+)
+
+// キーワード抽出の設定
+const (
+	minKeywordDocuments = 2 // 少なくともこの件数の記事に出てくる語だけを候補にする
+	maxKanjiKeyword     = 8
+	keywordTitleWeight  = 2.0
+	maxKeywordExamples  = 3
+)
+
+// keywordStopWords 記事によく出るが分野にはならない語
+var keywordStopWords = map[string]bool{
+	"記事": true, "今回": true, "発表": true, "公開": true, "開始": true, "提供": true, "対応": true,
+	"可能": true, "場合": true, "方法": true, "紹介": true, "解説": true, "予定": true, "以上": true,
+	"以下": true, "注目": true, "必要": true, "利用": true, "実現": true, "新た": true, "一部": true,
+	"情報": true, "関連": true, "話題": true, "本日": true, "昨日": true, "今年": true, "来年": true,
+	"ニュース": true, "サービス": true, "ユーザー": true, "タイトルなし": true,
+	"how": true, "why": true, "new": true, "now": true, "use": true, "using": true, "get": true,
+	"one": true, "all": true, "out": true, "just": true, "make": true, "here": true,
+	"http": true, "https": true, "www": true, "com": true,
+}
+
+// KeywordDocument キーワード抽出の対象とする記事
+type KeywordDocument struct {
+	Title string
+	Text  string
+}
+
+// KeywordSuggestion 分野の候補
+type KeywordSuggestion struct {
+	FieldName    string   `json:"field_name"`
+	Score        float64  `json:"score"`
+	ArticleCount int      `json:"article_count"`
+	Examples     []string `json:"examples"`
+}
+
+// SuggestKeywords 記事から頻出する語をTF-IDFで順位付けし、登録済みの分野を除いて上位limit件を返す
+// 記事ごとに「語の出現割合 × IDF」を求めて合計する。タイトル中の語は重みを大きくする
+func SuggestKeywords(docs []KeywordDocument, registered []models.Field, limit int) []KeywordSuggestion {
+	known := make(map[string]bool, len(registered))
+	for _, field := range registered {
+		known[normalizeText(field.FieldName)] = true
+	}
+
+	type docTerms struct {
+		weights map[string]float64
+		total   float64
+	}
+	parsed := make([]docTerms, 0, len(docs))
+	df := make(map[string]int)
+	display := make(map[string]string) // 照合用の語 → 表示に使う元の表記
+	examples := make(map[string][]string)
+
+	for _, doc := range docs {
+		terms := docTerms{weights: make(map[string]float64)}
+		add := func(text string, weight float64) {
+			for _, term := range ExtractKeywordCandidates(text) {
+				key := normalizeText(term)
+				if _, ok := display[key]; !ok {
+					display[key] = term
+				}
+				terms.weights[key] += weight
+				terms.total += weight
+			}
+		}
+		add(doc.Title, keywordTitleWeight)
+		add(doc.Text, 1.0)
+		if terms.total == 0 {
+			continue
+		}
+
+		for key := range terms.weights {
+			df[key]++
+			if len(examples[key]) < maxKeywordExamples && doc.Title != "" {
+				examples[key] = append(examples[key], doc.Title)
+			}
+		}
+		parsed = append(parsed, terms)
+	}
+
+	n := float64(len(parsed))
+	scores := make(map[string]float64)
+	for _, terms := range parsed {
+		for key, w := range terms.weights {
+			if df[key] < minKeywordDocuments || known[key] {
+				continue
+			}
+			idf := math.Log((n+1)/float64(df[key]+1)) + 1
+			scores[key] += w / terms.total * idf
+		}
+	}
+
+	suggestions := make([]KeywordSuggestion, 0, len(scores))
+	for key, score := range scores {
+		suggestions = append(suggestions, KeywordSuggestion{
+			FieldName:    models.NormalizeFieldName(display[key]),
+			Score:        math.Round(score*1000) / 1000,
+			ArticleCount: df[key],
+			Examples:     examples[key],
+		})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].FieldName < suggestions[j].FieldName
+	})
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// ExtractKeywordCandidates 文から分野の候補になる語を取り出す
+// 英数字の単語、カタカナの連続、漢字の連続（2〜8文字）を語とし、ひらがなや記号は区切りとして扱う
+// 漢字やカタカナと英数字が続く語（「生成AI」など）はつなげて1語にする
+func ExtractKeywordCandidates(text string) []string {
+	var candidates []string
+	var current []rune
+	currentClass := 0
+
+	flush := func() {
+		defer func() { current = current[:0]; currentClass = 0 }()
+		if len(current) == 0 {
+			return
+		}
+		term := string(current)
+		lower := strings.ToLower(term)
+		runes := len(current)
+
+		switch {
+		case keywordStopWords[lower] || keywordStopWords[term]:
+			return
+		case isDigitsOnly(term):
+			return
+		case currentClass == classASCII && (runes < 2 || englishStopWord(lower)):
+			return
+		case currentClass == classKanji && (runes < 2 || runes > maxKanjiKeyword):
+			return
+		case currentClass == classKatakana && runes < 2:
+			return
+		case currentClass == classMixed && !strings.ContainsFunc(term, isASCIILetter):
+			// 「2024年」のような数字と漢字の組み合わせは除く
+			return
+		}
+		candidates = append(candidates, term)
+	}
+
+	last := 0
+	for _, r := range normalizeWidth(text) {
+		class := keywordCharClass(r)
+		if class == 0 {
+			flush()
+			last = 0
+			continue
+		}
+		// 漢字とカタカナの境目では区切り、英数字との境目はつなげる
+		if len(current) > 0 && class != last && class != classASCII && last != classASCII {
+			flush()
+		}
+		if len(current) == 0 {
+			currentClass = class
+		} else if class != currentClass {
+			currentClass = classMixed
+		}
+		current = append(current, r)
+		last = class
+	}
+	flush()
+	return candidates
+}
+
+// 文字の種類
+const (
+	classASCII = iota + 1
+	classKatakana
+	classKanji
+	classMixed
+)
+
+func keywordCharClass(r rune) int {
+	switch {
+	case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+		return classASCII
+	case r == '+' || r == '#':
+		// C++ や C# のような語の一部
+		return classASCII
+	case unicode.Is(unicode.Katakana, r) || r == 'ー':
+		return classKatakana
+	case unicode.Is(unicode.Han, r):
+		return classKanji
+	}
+	return 0
+}
+
+// normalizeWidth 全角英数字を半角にする（大文字・小文字はそのまま）
+func normalizeWidth(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '！' && r <= '～' {
+			return r - 0xFEE0
+		}
+		return r
+	}, s)
+}
+
+func isASCIILetter(r rune) bool {
+	return r < unicode.MaxASCII && unicode.IsLetter(r)
+}
+
+func isDigitsOnly(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) && r != '+' && r != '#' {
+			return false
+		}
+	}
+	return true
+}
+
+func englishStopWord(w string) bool {
+	switch w {
+	case "the", "and", "for", "are", "was", "were", "with", "that", "this", "from", "have", "has",
+		"its", "into", "but", "not", "you", "your", "our", "their", "they", "will", "can", "also",
+		"been", "which", "about", "more", "than", "these", "those", "there", "what", "when", "who",
+		"is", "in", "on", "of", "to", "a", "an", "at", "by", "or", "as", "be", "it", "we", "vs":
+		return true
+	}
+	return false
+}
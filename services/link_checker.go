@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"login-app/models"
+)
+
+// リンク切れの確認の設定
+const (
+	DefaultLinkCheckInterval = time.Hour
+	linkRecheckAge           = 24 * time.Hour // 問題のなかったリンクを再確認するまでの時間
+	linkFailureBaseDelay     = time.Hour      // 失敗したリンクの再確認は1時間・2時間・4時間…と間隔を空ける
+	linkCheckConcurrency     = 4
+	linkCheckTimeout         = 15 * time.Second
+	linkCheckAttempts        = 3 // 1回の確認で一時的な失敗を再試行する回数
+	linkCheckRetryDelay      = 2 * time.Second
+	brokenLinkFailures       = 3                // 続けてこの回数失敗したリンクはリンク切れとみなす
+	linkRoomCheckTimeout     = 30 * time.Minute // 画面から始めたルームの確認を打ち切るまでの時間
+)
+
+// LinkChecker 未配信の記事のURLが開けるかを定期的に確認する
+type LinkChecker struct {
+	client   *http.Client
+	interval time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	running map[string]bool // 画面から始めた確認が実行中のルーム
+}
+
+// NewLinkChecker リンクの確認を作成（intervalが0以下の場合は既定の間隔を使う）
+func NewLinkChecker(client *http.Client, interval time.Duration) *LinkChecker {
+	if interval <= 0 {
+		interval = DefaultLinkCheckInterval
+	}
+	return &LinkChecker{client: client, interval: interval, now: time.Now, running: make(map[string]bool)}
+}
+
+// LinkCheckInterval 環境変数LINK_CHECK_INTERVAL（"1h" などの形式）から確認の間隔を読み込む
+func LinkCheckInterval() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("LINK_CHECK_INTERVAL")); err == nil && d > 0 {
+		return d
+	}
+	return DefaultLinkCheckInterval
+}
+
+// IsBrokenLink 記事のリンクが切れているかどうか
+// 404・410は1回で、接続できない場合やサーバーエラーは続けて失敗した場合にリンク切れとする
+func IsBrokenLink(article models.Article) bool {
+	if article.LinkStatus == nil {
+		return false
+	}
+	status := *article.LinkStatus
+	return status == http.StatusNotFound || status == http.StatusGone || article.LinkFailures >= brokenLinkFailures
+}
+
+// linkFailed 確認結果が失敗かどうか
+// 401・403・429などはボットを拒否しているだけのことが多いため失敗とはみなさない
+func linkFailed(status int) bool {
+	return status == 0 || status == http.StatusNotFound || status == http.StatusGone || status >= 500
+}
+
+// Run ctxが終了するまで一定間隔ですべてのルームの記事を確認する
+func (lc *LinkChecker) Run(ctx context.Context) {
+	ticker := time.NewTicker(lc.interval)
+	defer ticker.Stop()
+
+	for {
+		lc.CheckAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckAll すべてのルームについて確認の時期になった記事を確認する
+func (lc *LinkChecker) CheckAll(ctx context.Context) {
+	roomIDs, err := models.FetchRoomIDs()
+	if err != nil {
+		fmt.Printf("リンク確認の対象ルームの取得エラー: %v\n", err)
+		return
+	}
+
+	for _, roomID := range roomIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		checked, broken, err := lc.CheckRoom(ctx, roomID, false)
+		if err != nil {
+			fmt.Printf("リンクの確認エラー - Room ID: %s, Error: %v\n", roomID, err)
+		} else if checked > 0 {
+			fmt.Printf("リンクを確認しました - Room ID: %s, 確認: %d件, リンク切れ: %d件\n", roomID, checked, broken)
+		}
+	}
+}
+
+// StartRoomCheck ルームの未配信の記事のリンクをバックグラウンドですべて確認する
+// 件数が多いと数分かかるため、リクエストとは切り離して実行する。すでに実行中の場合はfalseを返す
+func (lc *LinkChecker) StartRoomCheck(roomID string) bool {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if lc.running[roomID] {
+		return false
+	}
+	lc.running[roomID] = true
+
+	go func() {
+		defer func() {
+			lc.mu.Lock()
+			delete(lc.running, roomID)
+			lc.mu.Unlock()
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), linkRoomCheckTimeout)
+		defer cancel()
+		checked, broken, err := lc.CheckRoom(ctx, roomID, true)
+		if err != nil {
+			fmt.Printf("リンクの確認エラー - Room ID: %s, Error: %v\n", roomID, err)
+			return
+		}
+		fmt.Printf("リンクを確認しました - Room ID: %s, 確認: %d件, リンク切れ: %d件\n", roomID, checked, broken)
+	}()
+	return true
+}
+
+// RoomCheckRunning ルームのリンクの確認が実行中かどうか
+func (lc *LinkChecker) RoomCheckRunning(roomID string) bool {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.running[roomID]
+}
+
+// CheckRoom ルームの未配信の記事のリンクを確認し、確認した件数とリンク切れの件数を返す
+// forceがfalseの場合は確認の時期になった記事だけを確認する
+func (lc *LinkChecker) CheckRoom(ctx context.Context, roomID string, force bool) (int, int, error) {
+	articles, err := models.FetchArticles(roomID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	now := lc.now().UTC()
+	var targets []models.Article
+	for _, article := range articles {
+		if article.DeliveredAt != nil || models.ParseArticleContent(article.Content).URL == "" {
+			continue
+		}
+		if force || lc.due(article, now) {
+			targets = append(targets, article)
+		}
+	}
+
+	// 同時に確認する件数を制限する
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		broken int
+		sem    = make(chan struct{}, linkCheckConcurrency)
+	)
+	for _, article := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(article models.Article) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if lc.checkArticle(ctx, &article) && IsBrokenLink(article) {
+				mu.Lock()
+				broken++
+				mu.Unlock()
+			}
+		}(article)
+	}
+	wg.Wait()
+	return len(targets), broken, nil
+}
+
+// due 記事のリンクを確認する時期かどうか
+// 失敗が続いているリンクは失敗した回数に応じて間隔を空ける（最大でlinkRecheckAge）
+func (lc *LinkChecker) due(article models.Article, now time.Time) bool {
+	if article.LinkCheckedAt == nil {
+		return true
+	}
+	wait := linkRecheckAge
+	if article.LinkFailures > 0 {
+		wait = linkFailureBaseDelay << (article.LinkFailures - 1)
+		if wait > linkRecheckAge || wait <= 0 {
+			wait = linkRecheckAge
+		}
+	}
+	return now.Sub(*article.LinkCheckedAt) >= wait
+}
+
+// checkArticle 記事のリンクを確認して結果を保存し、articleにも反映する（保存できなかった場合はfalse）
+func (lc *LinkChecker) checkArticle(ctx context.Context, article *models.Article) bool {
+	articleURL := models.ParseArticleContent(article.Content).URL
+	status, err := lc.CheckURL(ctx, articleURL)
+	if ctx.Err() != nil {
+		return false
+	}
+
+	message := ""
+	if err != nil {
+		message = err.Error()
+	}
+	failures := 0
+	if linkFailed(status) {
+		failures = article.LinkFailures + 1
+	}
+	checkedAt := lc.now().UTC()
+
+	if err := models.UpdateArticleLinkCheck(article.ArticleID, status, message, failures, checkedAt); err != nil {
+		fmt.Printf("リンクの確認結果の保存エラー - Article ID: %d, Error: %v\n", article.ArticleID, err)
+		return false
+	}
+	article.LinkStatus = &status
+	article.LinkError = message
+	article.LinkFailures = failures
+	article.LinkCheckedAt = &checkedAt
+	return true
+}
+
+// CheckURL URLにHEADで問い合わせ、失敗した場合はGETで確かめてHTTPステータスを返す（接続できない場合は0とエラー）
+// 接続エラー・429・サーバーエラーは間隔を空けて再試行する
+func (lc *LinkChecker) CheckURL(ctx context.Context, rawURL string) (int, error) {
+	var status int
+	var err error
+	for attempt := 0; attempt < linkCheckAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			case <-time.After(linkCheckRetryDelay << (attempt - 1)):
+			}
+		}
+
+		// HEADに対応していないサーバーもあるので、エラーになった場合はGETで確かめる
+		status, err = lc.request(ctx, http.MethodHead, rawURL)
+		if err != nil || status >= 400 {
+			status, err = lc.request(ctx, http.MethodGet, rawURL)
+		}
+
+		if errors.Is(err, ErrForbiddenAddress) {
+			return 0, err
+		}
+		if err == nil && status != http.StatusTooManyRequests && status < 500 {
+			return status, nil
+		}
+	}
+	return status, err
+}
+
+func (lc *LinkChecker) request(ctx context.Context, method, rawURL string) (int, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, linkCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, method, rawURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "login-app-link-checker/1.0")
+
+	resp, err := lc.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// 接続を再利用できるよう、本文は少しだけ読んで捨てる
+	io.CopyN(io.Discard, resp.Body, 4096)
+	return resp.StatusCode, nil
+}
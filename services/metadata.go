@@ -0,0 +1,336 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"login-app/models"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// メタデータ取得の設定
+const (
+	metadataFetchTimeout  = 15 * time.Second
+	maxMetadataPageSize   = 1 << 20
+	metadataCacheTTL      = 7 * 24 * time.Hour
+	metadataErrorCacheTTL = 6 * time.Hour
+	maxMetadataTextLength = 400
+)
+
+// MetadataFetcher 記事URLのページからOpenGraphなどのメタデータを取得する
+// 取得結果はpage_metadataにキャッシュし、有効期限内は再取得しない
+type MetadataFetcher struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewMetadataFetcher メタデータ取得の処理を作成
+// clientにはNewSafeHTTPClientで作成した内部ネットワークに接続しないクライアントを渡す
+func NewMetadataFetcher(client *http.Client) *MetadataFetcher {
+	return &MetadataFetcher{client: client, now: time.Now}
+}
+
+// Fetch URLのメタデータを取得する（キャッシュがあればそれを返す）
+func (f *MetadataFetcher) Fetch(ctx context.Context, rawURL string) (*models.PageMetadata, error) {
+	normalized, err := models.NormalizeArticleURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, err := models.FetchPageMetadata(normalized)
+	if err != nil {
+		fmt.Printf("メタデータのキャッシュ取得エラー: %v\n", err)
+	}
+	if cached != nil && f.fresh(*cached) {
+		if cached.Error != "" {
+			return nil, fmt.Errorf("%s", cached.Error)
+		}
+		return cached, nil
+	}
+
+	meta, fetchErr := f.fetchPage(ctx, strings.TrimSpace(rawURL))
+	if meta == nil {
+		meta = &models.PageMetadata{}
+	}
+	meta.URL = normalized
+	meta.FetchedAt = f.now().UTC()
+	if fetchErr != nil {
+		meta.Error = fetchErr.Error()
+	}
+	if err := models.SavePageMetadata(*meta); err != nil {
+		fmt.Printf("メタデータのキャッシュ保存エラー: %v\n", err)
+	}
+
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+	return meta, nil
+}
+
+// fresh キャッシュが有効期限内かどうか（取得に失敗した結果は短めに保持する）
+func (f *MetadataFetcher) fresh(meta models.PageMetadata) bool {
+	ttl := metadataCacheTTL
+	if meta.Error != "" {
+		ttl = metadataErrorCacheTTL
+	}
+	return f.now().Sub(meta.FetchedAt) < ttl
+}
+
+// fetchPage ページを取得してメタデータを取り出す
+func (f *MetadataFetcher) fetchPage(ctx context.Context, pageURL string) (*models.PageMetadata, error) {
+	var meta *models.PageMetadata
+	err := f.withPage(ctx, pageURL, func(body io.Reader, base *url.URL) error {
+		meta = ParseHTMLMetadata(body, base)
+		return nil
+	})
+	return meta, err
+}
+
+// FetchText ページを取得して本文のテキストを取り出す（要約に使う）
+func (f *MetadataFetcher) FetchText(ctx context.Context, pageURL string) (string, error) {
+	var text string
+	err := f.withPage(ctx, strings.TrimSpace(pageURL), func(body io.Reader, _ *url.URL) error {
+		text = ExtractHTMLText(body)
+		return nil
+	})
+	return text, err
+}
+
+// withPage HTMLのページを取得し、文字コードを変換した本文とリダイレクト後のURLをfnに渡す
+func (f *MetadataFetcher) withPage(ctx context.Context, pageURL string, fn func(body io.Reader, base *url.URL) error) error {
+	ctx, cancel := context.WithTimeout(ctx, metadataFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return fmt.Errorf("リクエストの作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+	req.Header.Set("User-Agent", "login-app-metadata-fetcher/1.0")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ページの取得に失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ページの取得に失敗しました（Status: %d）", resp.StatusCode)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "" &&
+		mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return fmt.Errorf("HTMLではありません（Content-Type: %s）", mediaType)
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, maxMetadataPageSize), contentType)
+	if err != nil {
+		return fmt.Errorf("文字コードの判定に失敗しました: %w", err)
+	}
+
+	// リダイレクト後のURLを基準に相対URLを解決する
+	return fn(body, resp.Request.URL)
+}
+
+// ExtractHTMLText 見出し・段落・箇条書きの中のテキストを1行ずつ取り出す
+// script・style・nav・header・footer・aside の中は本文ではないものとして除く
+func ExtractHTMLText(r io.Reader) string {
+	skipTags := map[string]bool{"script": true, "style": true, "nav": true, "header": true, "footer": true, "aside": true, "noscript": true, "form": true}
+	blockTags := map[string]bool{"p": true, "h1": true, "h2": true, "h3": true, "li": true, "blockquote": true}
+
+	var lines []string
+	var current strings.Builder
+	skipDepth, blockDepth := 0, 0
+	flush := func() {
+		if line := strings.Join(strings.Fields(current.String()), " "); line != "" {
+			lines = append(lines, line)
+		}
+		current.Reset()
+	}
+
+	tokenizer := html.NewTokenizer(r)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			flush()
+			return strings.Join(lines, "\n")
+		case html.StartTagToken:
+			name, _ := tokenizer.TagName()
+			tag := string(name)
+			if skipTags[tag] {
+				skipDepth++
+			} else if blockTags[tag] {
+				flush()
+				blockDepth++
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			tag := string(name)
+			if skipTags[tag] && skipDepth > 0 {
+				skipDepth--
+			} else if blockTags[tag] && blockDepth > 0 {
+				blockDepth--
+				flush()
+			}
+		case html.TextToken:
+			if skipDepth == 0 && blockDepth > 0 {
+				current.Write(tokenizer.Text())
+			}
+		}
+	}
+}
+
+// ParseHTMLMetadata HTMLのhead要素からタイトル・説明・画像・正規URL・公開日時を取り出す
+// OpenGraphの値を優先し、なければtitle要素やmeta descriptionを使う
+func ParseHTMLMetadata(r io.Reader, base *url.URL) *models.PageMetadata {
+	values := make(map[string]string)
+	var title strings.Builder
+	inTitle := false
+
+	tokenizer := html.NewTokenizer(r)
+loop:
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			break loop
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			tag := string(name)
+			if tag == "body" {
+				break loop
+			}
+			if tag == "title" {
+				inTitle = true
+				continue
+			}
+			if !hasAttr || (tag != "meta" && tag != "link") {
+				continue
+			}
+
+			attrs := make(map[string]string)
+			for {
+				key, val, more := tokenizer.TagAttr()
+				attrs[strings.ToLower(string(key))] = string(val)
+				if !more {
+					break
+				}
+			}
+
+			if tag == "link" {
+				if strings.EqualFold(attrs["rel"], "canonical") {
+					setOnce(values, "canonical", attrs["href"])
+				}
+				continue
+			}
+			key := strings.ToLower(attrs["property"])
+			if key == "" {
+				key = strings.ToLower(attrs["name"])
+			}
+			if key != "" {
+				setOnce(values, key, attrs["content"])
+			}
+		case html.TextToken:
+			if inTitle {
+				title.Write(tokenizer.Text())
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			if string(name) == "title" {
+				inTitle = false
+			} else if string(name) == "head" {
+				break loop
+			}
+		}
+	}
+
+	meta := &models.PageMetadata{
+		Title:        firstNonEmpty(values["og:title"], values["twitter:title"], title.String()),
+		Description:  firstNonEmpty(values["og:description"], values["twitter:description"], values["description"]),
+		Image:        resolveURL(base, firstNonEmpty(values["og:image"], values["twitter:image"])),
+		CanonicalURL: resolveURL(base, firstNonEmpty(values["canonical"], values["og:url"])),
+		PublishedAt:  parseFeedTime(values["article:published_time"], values["og:published_time"], values["date"]),
+	}
+	// 文字参照はトークナイザーで展開済みのため、展開して現れたタグを取り除く
+	meta.Title = truncateRunes(stripHTMLTags(meta.Title), maxMetadataTextLength)
+	meta.Description = truncateRunes(stripHTMLTags(meta.Description), maxMetadataTextLength)
+	return meta
+}
+
+// EnrichArticle タイトルまたは要約が空の記事を、ページのメタデータで補完して保存する
+// 補完した場合はtrueを返す
+func (f *MetadataFetcher) EnrichArticle(ctx context.Context, article *models.Article) (bool, error) {
+	content := models.ParseArticleContent(article.Content)
+	if content.URL == "" || (content.Title != "" && content.Summary != "") {
+		return false, nil
+	}
+
+	meta, err := f.Fetch(ctx, content.URL)
+	if err != nil {
+		return false, err
+	}
+
+	changed := false
+	if content.Title == "" && meta.Title != "" {
+		content.Title = meta.Title
+		changed = true
+	}
+	if content.Summary == "" && meta.Description != "" {
+		content.Summary = meta.Description
+		changed = true
+	}
+	if !changed {
+		return false, nil
+	}
+
+	rendered := models.RenderArticleContent(content)
+	if err := models.UpdateArticle(article.ArticleID, map[string]interface{}{"content": rendered}); err != nil {
+		return false, err
+	}
+	article.Content = rendered
+	if err := RescoreArticle(article); err != nil {
+		fmt.Printf("記事の再採点エラー - Article ID: %d, Error: %v\n", article.ArticleID, err)
+	}
+	return true, nil
+}
+
+// setOnce 最初に見つかった空でない値だけを記録する
+func setOnce(values map[string]string, key, value string) {
+	if _, ok := values[key]; !ok && strings.TrimSpace(value) != "" {
+		values[key] = strings.TrimSpace(value)
+	}
+}
+
+// resolveURL 相対URLをbaseを基準に絶対URLにする（http/https以外は空にする）
+func resolveURL(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"login-app/models"
+)
+
+// 保持ルールの設定
+const (
+	MaxRetentionDays         = 3650
+	MaxRetentionCount        = 10000
+	DefaultRetentionInterval = time.Hour
+)
+
+// 削除する理由
+const (
+	PruneReasonDelivered = "delivered" // 配信済み
+	PruneReasonExpired   = "expired"   // 保持期間切れ
+	PruneReasonOverflow  = "overflow"  // 件数超過
+)
+
+// PruneCandidate 保持ルールによって削除される記事
+type PruneCandidate struct {
+	ArticleID int64  `json:"article_id"`
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	Reason    string `json:"reason"`
+}
+
+// ValidateRetentionRule 保持ルールの値を検証する
+func ValidateRetentionRule(rule models.RetentionRule) error {
+	switch {
+	case rule.MaxAgeDays < 0 || rule.MaxAgeDays > MaxRetentionDays:
+		return fmt.Errorf("保持日数は0から%dの間で指定してください", MaxRetentionDays)
+	case rule.MaxCount < 0 || rule.MaxCount > MaxRetentionCount:
+		return fmt.Errorf("最大件数は0から%dの間で指定してください", MaxRetentionCount)
+	case rule.DeliveredKeepDays < 0 || rule.DeliveredKeepDays > MaxRetentionDays:
+		return fmt.Errorf("配信後に残す日数は0から%dの間で指定してください", MaxRetentionDays)
+	}
+	return nil
+}
+
+// PlanRetention 保持ルールによって削除する記事を選ぶ
+// スター付きの記事と配信順の先頭に固定した記事は削除しない。配信済み・保持期間切れの記事を除いた後、
+// 件数が上限を超えていれば配信済みの記事、古い記事の順に削除する
+// 保持期間はcreated_atから数える。created_atの列より前からある記事は採点・配信の時刻で埋めている
+// （migrations/019）ため、実際に予約した時刻より新しく扱われることがある
+func PlanRetention(rule models.RetentionRule, articles []models.Article, states map[int64]models.ArticleState, now time.Time) []PruneCandidate {
+	var candidates []PruneCandidate
+	var remaining []models.Article
+	add := func(article models.Article, reason string) {
+		content := models.ParseArticleContent(article.Content)
+		candidates = append(candidates, PruneCandidate{
+			ArticleID: article.ArticleID,
+			Title:     content.Title,
+			URL:       content.URL,
+			Reason:    reason,
+		})
+	}
+
+	protected := func(article models.Article) bool {
+		return states[article.ArticleID].IsStarred || article.Pinned
+	}
+
+	for _, article := range articles {
+		if protected(article) {
+			continue
+		}
+		switch {
+		case rule.DeleteAfterDelivery && article.DeliveredAt != nil &&
+			now.Sub(*article.DeliveredAt) >= time.Duration(rule.DeliveredKeepDays)*24*time.Hour:
+			add(article, PruneReasonDelivered)
+		case rule.MaxAgeDays > 0 && article.CreatedAt != nil &&
+			now.Sub(*article.CreatedAt) >= time.Duration(rule.MaxAgeDays)*24*time.Hour:
+			add(article, PruneReasonExpired)
+		default:
+			remaining = append(remaining, article)
+		}
+	}
+
+	// スター付き・固定した記事も件数には含める
+	kept := len(remaining)
+	for _, article := range articles {
+		if protected(article) {
+			kept++
+		}
+	}
+	if rule.MaxCount > 0 && kept > rule.MaxCount {
+		sort.SliceStable(remaining, func(i, j int) bool {
+			a, b := remaining[i], remaining[j]
+			if (a.DeliveredAt != nil) != (b.DeliveredAt != nil) {
+				return a.DeliveredAt != nil
+			}
+			return a.ArticleID < b.ArticleID
+		})
+		excess := kept - rule.MaxCount
+		for i := 0; i < excess && i < len(remaining); i++ {
+			add(remaining[i], PruneReasonOverflow)
+		}
+	}
+	return candidates
+}
+
+// PruneRoom ルームの保持ルールに従って記事を削除し、削除した（dryRunの場合は削除する）記事を返す
+// 削除した記事は履歴に削除済みとして残す
+func PruneRoom(roomID string, dryRun bool, now time.Time) ([]PruneCandidate, error) {
+	rule, err := models.FetchRetentionRule(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if !rule.Active() {
+		return []PruneCandidate{}, nil
+	}
+
+	articles, err := models.FetchArticles(roomID)
+	if err != nil {
+		return nil, err
+	}
+	states, err := models.FetchArticleStates(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := PlanRetention(*rule, articles, states, now)
+	if candidates == nil {
+		candidates = []PruneCandidate{}
+	}
+	if dryRun {
+		return candidates, nil
+	}
+
+	byID := make(map[int64]models.Article, len(articles))
+	for _, article := range articles {
+		byID[article.ArticleID] = article
+	}
+	var errs []error
+	pruned := make([]PruneCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if err := models.ArchiveArticle(byID[candidate.ArticleID], models.HistoryEventDeleted, now); err != nil {
+			errs = append(errs, fmt.Errorf("記事ID %d の履歴の保存に失敗しました: %w", candidate.ArticleID, err))
+			continue
+		}
+		if err := models.DeleteArticle(roomID, candidate.ArticleID); err != nil {
+			errs = append(errs, fmt.Errorf("記事ID %d の削除に失敗しました: %w", candidate.ArticleID, err))
+			continue
+		}
+		pruned = append(pruned, candidate)
+	}
+	if err := models.MarkRetentionPruned(roomID, now); err != nil {
+		errs = append(errs, err)
+	}
+	return pruned, errors.Join(errs...)
+}
+
+// RetentionPruner 保持ルールを設定しているルームの記事を定期的に削除する
+type RetentionPruner struct {
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewRetentionPruner 削除の処理を作成（intervalが0以下の場合は既定の間隔を使う）
+func NewRetentionPruner(interval time.Duration) *RetentionPruner {
+	if interval <= 0 {
+		interval = DefaultRetentionInterval
+	}
+	return &RetentionPruner{interval: interval, now: time.Now}
+}
+
+// Run ctxが終了するまで一定間隔ですべてのルームに保持ルールを適用する
+func (p *RetentionPruner) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.PruneAll()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PruneAll 保持ルールを設定しているすべてのルームの記事を削除する
+func (p *RetentionPruner) PruneAll() {
+	rules, err := models.FetchRetentionRules()
+	if err != nil {
+		fmt.Printf("保持ルールの取得エラー: %v\n", err)
+		return
+	}
+
+	now := p.now().UTC()
+	for _, rule := range rules {
+		if !rule.Active() {
+			continue
+		}
+		pruned, err := PruneRoom(rule.RoomID, false, now)
+		if err != nil {
+			fmt.Printf("保持ルールの適用エラー - Room ID: %s, Error: %v\n", rule.RoomID, err)
+		}
+		if len(pruned) > 0 {
+			fmt.Printf("保持ルールにより%d件の記事を削除しました - Room ID: %s\n", len(pruned), rule.RoomID)
+		}
+	}
+}
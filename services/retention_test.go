@@ -0,0 +1,33 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"login-app/models"
+)
+
+func TestPlanRetentionKeepsPinnedAndStarred(t *testing.T) {
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	old := now.AddDate(0, 0, -30)
+	articles := make([]models.Article, 4)
+	for i := range articles {
+		articles[i] = testArticle(int64(i+1), "")
+		articles[i].CreatedAt = &old
+	}
+	articles[0].Pinned = true
+	states := map[int64]models.ArticleState{2: {IsStarred: true}}
+
+	candidates := PlanRetention(models.RetentionRule{MaxAgeDays: 7}, articles, states, now)
+	if len(candidates) != 2 || candidates[0].ArticleID != 3 || candidates[1].ArticleID != 4 {
+		t.Errorf("固定・スター付きの記事は保持期間を過ぎても削除しない: %+v", candidates)
+	}
+
+	for i := range articles {
+		articles[i].CreatedAt = &now
+	}
+	candidates = PlanRetention(models.RetentionRule{MaxCount: 3}, articles, states, now)
+	if len(candidates) != 1 || candidates[0].ArticleID != 3 || candidates[0].Reason != PruneReasonOverflow {
+		t.Errorf("固定・スター付きの記事も件数に含め、それ以外から削除する: %+v", candidates)
+	}
+}
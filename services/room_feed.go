@@ -0,0 +1,279 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"login-app/models"
+)
+
+// 購読フィードに載せる記事の件数
+const roomFeedItemLimit = 50
+
+// 購読フィードに載せる記事の状態
+const (
+	RoomFeedStatusReserved  = "reserved"  // 未配信の予約記事
+	RoomFeedStatusDelivered = "delivered" // 配信済みの記事
+)
+
+// GenerateFeedSecret 購読フィードのURLに含める秘密の値を作成
+func GenerateFeedSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("秘密の値の作成に失敗しました: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashFeedSecret 保存・照合に使う秘密の値のハッシュ
+func HashFeedSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyFeedSecret URLの秘密の値がルームに発行したものと一致するかどうか
+func VerifyFeedSecret(stored *models.RoomFeedSecret, secret string) bool {
+	if stored == nil || secret == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(HashFeedSecret(secret)), []byte(stored.SecretHash)) == 1
+}
+
+// RoomFeedItem 購読フィードの記事1件
+type RoomFeedItem struct {
+	ArticleID     int64
+	Title         string
+	URL           string
+	Summary       string
+	MatchedFields []string
+	Status        string
+	Published     time.Time
+}
+
+// RoomFeed ルームの購読フィード
+type RoomFeed struct {
+	RoomID  string
+	Title   string
+	HomeURL string // 記事一覧ページ
+	FeedURL string // このフィード自身のURL
+	Updated time.Time
+	Items   []RoomFeedItem
+}
+
+// BuildRoomFeed ルームの予約記事と配信済みの記事を新しい順にまとめる
+// statusが空の場合は両方を載せる。linkは記事URLを転送用リンクに書き換える関数（nilの場合は書き換えない）
+func BuildRoomFeed(roomID, status string, link func(articleID int64, articleURL string) string) ([]RoomFeedItem, error) {
+	var items []RoomFeedItem
+	seen := make(map[int64]bool)
+
+	articles, err := models.FetchArticles(roomID)
+	if err != nil {
+		return nil, err
+	}
+	for _, article := range articles {
+		item := RoomFeedItem{
+			ArticleID:     article.ArticleID,
+			MatchedFields: article.MatchedFields,
+			Status:        RoomFeedStatusReserved,
+		}
+		parsed := models.ParseArticleContent(article.Content)
+		item.Title, item.URL, item.Summary = parsed.Title, parsed.URL, parsed.Summary
+		switch {
+		case article.DeliveredAt != nil:
+			item.Status = RoomFeedStatusDelivered
+			item.Published = *article.DeliveredAt
+		case article.CreatedAt != nil:
+			item.Published = *article.CreatedAt
+		case article.ScoredAt != nil:
+			item.Published = *article.ScoredAt
+		}
+		seen[article.ArticleID] = true
+		if status == "" || status == item.Status {
+			items = append(items, item)
+		}
+	}
+
+	// 配信後に予約記事から削除された記事は履歴から載せる
+	if status == "" || status == RoomFeedStatusDelivered {
+		histories, _, err := models.SearchArticleHistory(roomID, models.HistoryQuery{
+			Event: models.HistoryEventDelivered,
+			Limit: roomFeedItemLimit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, history := range histories {
+			if seen[history.ArticleID] || history.DeliveredAt == nil {
+				continue
+			}
+			items = append(items, RoomFeedItem{
+				ArticleID:     history.ArticleID,
+				Title:         history.Title,
+				URL:           history.URL,
+				Summary:       history.Summary,
+				MatchedFields: history.MatchedFields,
+				Status:        RoomFeedStatusDelivered,
+				Published:     *history.DeliveredAt,
+			})
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if !items[i].Published.Equal(items[j].Published) {
+			return items[i].Published.After(items[j].Published)
+		}
+		return items[i].ArticleID > items[j].ArticleID
+	})
+	if len(items) > roomFeedItemLimit {
+		items = items[:roomFeedItemLimit]
+	}
+
+	if link != nil {
+		for i := range items {
+			if items[i].URL != "" {
+				items[i].URL = link(items[i].ArticleID, items[i].URL)
+			}
+		}
+	}
+	return items, nil
+}
+
+// itemID 記事ごとに変わらないフィードの項目ID
+func (f RoomFeed) itemID(item RoomFeedItem) string {
+	return fmt.Sprintf("%s#article-%d", f.HomeURL, item.ArticleID)
+}
+
+// displayTitle タイトルのない記事はURLを表示する
+func (item RoomFeedItem) displayTitle() string {
+	if item.Title != "" {
+		return item.Title
+	}
+	if item.URL != "" {
+		return item.URL
+	}
+	return "タイトルなし"
+}
+
+// 書き出すAtom 1.0の要素（取り込み用のatomEntryとは別に、必須の要素を含める）
+type outAtomFeed struct {
+	XMLName xml.Name       `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string         `xml:"id"`
+	Title   string         `xml:"title"`
+	Updated string         `xml:"updated"`
+	Links   []outAtomLink  `xml:"link"`
+	Entries []outAtomEntry `xml:"entry"`
+}
+
+type outAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type outAtomEntry struct {
+	ID         string            `xml:"id"`
+	Title      string            `xml:"title"`
+	Updated    string            `xml:"updated"`
+	Published  string            `xml:"published"`
+	Link       *outAtomLink      `xml:"link,omitempty"`
+	Summary    string            `xml:"summary,omitempty"`
+	Categories []outAtomCategory `xml:"category"`
+}
+
+type outAtomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// WriteAtom 購読フィードをAtom形式で書き出す
+func WriteAtom(w io.Writer, feed RoomFeed) error {
+	out := outAtomFeed{
+		ID:      fmt.Sprintf("%s#room-%s", feed.HomeURL, feed.RoomID),
+		Title:   feed.Title,
+		Updated: feed.Updated.UTC().Format(time.RFC3339),
+		Links: []outAtomLink{
+			{Href: feed.FeedURL, Rel: "self"},
+			{Href: feed.HomeURL, Rel: "alternate"},
+		},
+	}
+	for _, item := range feed.Items {
+		entry := outAtomEntry{
+			ID:        feed.itemID(item),
+			Title:     item.displayTitle(),
+			Updated:   item.Published.UTC().Format(time.RFC3339),
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Summary:   item.Summary,
+		}
+		if item.URL != "" {
+			entry.Link = &outAtomLink{Href: item.URL, Rel: "alternate"}
+		}
+		for _, field := range item.MatchedFields {
+			entry.Categories = append(entry.Categories, outAtomCategory{Term: field})
+		}
+		out.Entries = append(out.Entries, entry)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(out)
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string   `json:"id"`
+	URL           string   `json:"url,omitempty"`
+	Title         string   `json:"title"`
+	ContentText   string   `json:"content_text"`
+	Summary       string   `json:"summary,omitempty"`
+	DatePublished string   `json:"date_published"`
+	Tags          []string `json:"tags,omitempty"`
+}
+
+// WriteJSONFeed 購読フィードをJSON Feed 1.1形式で書き出す
+func WriteJSONFeed(w io.Writer, feed RoomFeed) error {
+	out := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feed.Title,
+		HomePageURL: feed.HomeURL,
+		FeedURL:     feed.FeedURL,
+		Items:       make([]jsonFeedItem, 0, len(feed.Items)),
+	}
+	for _, item := range feed.Items {
+		// content_textは必須のため、要約がない記事はURLを入れる
+		text := item.Summary
+		if text == "" {
+			text = item.URL
+		}
+		out.Items = append(out.Items, jsonFeedItem{
+			ID:            feed.itemID(item),
+			URL:           item.URL,
+			Title:         item.displayTitle(),
+			ContentText:   text,
+			Summary:       item.Summary,
+			DatePublished: item.Published.UTC().Format(time.RFC3339),
+			Tags:          item.MatchedFields,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// 外部URL取得の設定
+const maxFetchRedirects = 5
+
+// ErrForbiddenAddress 内部ネットワークなど取得を許可しない宛先へ接続しようとした場合のエラー
+var ErrForbiddenAddress = errors.New("取得を許可されていない宛先です")
+
+// NewSafeHTTPClient 利用者が指定した外部URLを取得するためのHTTPクライアントを作成する
+// 名前解決後の接続先がループバック・プライベート・リンクローカルなどのアドレスの場合は接続しない（SSRF対策）
+// 環境変数ALLOW_PRIVATE_FETCH=trueの場合は開発用にこの制限を外す
+func NewSafeHTTPClient(timeout time.Duration) *http.Client {
+	allowPrivate, _ := strconv.ParseBool(os.Getenv("ALLOW_PRIVATE_FETCH"))
+
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if !allowPrivate {
+		// 接続直前に実際の接続先アドレスを検証するので、DNSリバインディングにも対応できる
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+			}
+			return nil
+		}
+	}
+
+	transport := &http.Transport{
+		Proxy: nil,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, addr)
+		},
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          20,
+		IdleConnTimeout:       90 * time.Second,
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxFetchRedirects {
+				return fmt.Errorf("リダイレクトが多すぎます")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("リダイレクト先のスキーム %q は許可されていません", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+// isPublicIP インターネット上の通常の宛先アドレスかどうか
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		// 100.64.0.0/10（CGNAT）と0.0.0.0/8、ブロードキャスト
+		if ip4[0] == 0 || (ip4[0] == 100 && ip4[1]&0xc0 == 64) || ip4.Equal(net.IPv4bcast) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"login-app/models"
+)
+
+// 一致した箇所ごとの重み（優先度に掛け合わせる）
+const (
+	titleMatchWeight   = 3.0
+	tagMatchWeight     = 2.0
+	summaryMatchWeight = 1.0
+)
+
+// ScoreResult 記事1件のスコアリング結果
+type ScoreResult struct {
+	Score         float64
+	MatchedFields []string
+}
+
+// ScoreArticle 記事のタイトル・要約・タグを登録分野と照合してスコアを計算する
+// 分野ごとに「一致した箇所の重みの合計 × 優先度」を加算し、一致した分野は寄与の大きい順に返す
+func ScoreArticle(content models.ArticleContent, fields []models.Field) ScoreResult {
+	title := normalizeText(content.Title)
+	summary := normalizeText(content.Summary)
+	labels := normalizeText(content.Field + " " + strings.Join(content.Tags, " "))
+
+	type contribution struct {
+		name  string
+		score float64
+	}
+	var contributions []contribution
+	total := 0.0
+
+	for _, field := range fields {
+		term := normalizeText(field.FieldName)
+		if term == "" {
+			continue
+		}
+
+		weight := 0.0
+		if containsTerm(title, term) {
+			weight += titleMatchWeight
+		}
+		if containsTerm(labels, term) {
+			weight += tagMatchWeight
+		}
+		if containsTerm(summary, term) {
+			weight += summaryMatchWeight
+		}
+		if weight == 0 {
+			continue
+		}
+
+		score := weight * float64(field.Priority)
+		total += score
+		contributions = append(contributions, contribution{name: field.FieldName, score: score})
+	}
+
+	sort.SliceStable(contributions, func(i, j int) bool {
+		return contributions[i].score > contributions[j].score
+	})
+
+	result := ScoreResult{
+		Score:         math.Round(total*100) / 100,
+		MatchedFields: make([]string, 0, len(contributions)),
+	}
+	for _, c := range contributions {
+		result.MatchedFields = append(result.MatchedFields, c.name)
+	}
+	return result
+}
+
+// ScoreStoredArticle 登録済みの記事を採点する（手動で外した分野は照合しない）
+func ScoreStoredArticle(article models.Article, fields []models.Field) ScoreResult {
+	if len(article.DetachedFields) > 0 {
+		detached := make(map[string]bool, len(article.DetachedFields))
+		for _, name := range article.DetachedFields {
+			detached[name] = true
+		}
+		filtered := make([]models.Field, 0, len(fields))
+		for _, field := range fields {
+			if !detached[field.FieldName] {
+				filtered = append(filtered, field)
+			}
+		}
+		fields = filtered
+	}
+	return ScoreArticle(models.ParseArticleContent(article.Content), fields)
+}
+
+// RescoreRoom ルームの記事を現在の分野で採点し直し、スコアが変わった記事だけを保存する
+// 分野を変更した処理と配信の前に呼ぶ
+// 採点後の記事一覧を返す
+func RescoreRoom(roomID string) ([]models.Article, error) {
+	articles, err := models.FetchArticles(roomID)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := models.FetchFields(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	var changed []models.Article
+	for i := range articles {
+		result := ScoreStoredArticle(articles[i], fields)
+		if articles[i].ScoredAt != nil && articles[i].Score == result.Score &&
+			equalStrings(articles[i].MatchedFields, result.MatchedFields) {
+			continue
+		}
+		articles[i].Score = result.Score
+		articles[i].MatchedFields = result.MatchedFields
+		articles[i].ScoredAt = &now
+		changed = append(changed, articles[i])
+	}
+
+	if err := models.UpdateArticleScores(changed); err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
+// RescoreArticle 内容を変えた記事1件を現在の分野で採点し直して保存する
+// 記事一覧の取得時には採点しないため、記事の内容を変えた処理から呼ぶ
+func RescoreArticle(article *models.Article) error {
+	fields, err := models.FetchFields(article.RoomID)
+	if err != nil {
+		return err
+	}
+	return saveArticleScore(article, fields)
+}
+
+// saveArticleScore 記事をfieldsで採点し、スコアと一致した分野を保存する
+func saveArticleScore(article *models.Article, fields []models.Field) error {
+	result := ScoreStoredArticle(*article, fields)
+	now := time.Now().UTC()
+	article.Score = result.Score
+	article.MatchedFields = result.MatchedFields
+	article.ScoredAt = &now
+	return models.UpdateArticleScores([]models.Article{*article})
+}
+
+// SortArticlesByScore スコアの高い順に並べ替える（同点の場合は新しい記事を先にする）
+func SortArticlesByScore(articles []models.Article) {
+	sort.SliceStable(articles, func(i, j int) bool {
+		if articles[i].Score != articles[j].Score {
+			return articles[i].Score > articles[j].Score
+		}
+		return articles[i].ArticleID > articles[j].ArticleID
+	})
+}
+
+// SortArticlesByQueue 配信順に並べ替える
+// 未配信の記事を先にし、その中では固定した記事、並べ替えた位置の順、スコアの高い順とする
+func SortArticlesByQueue(articles []models.Article) {
+	sort.SliceStable(articles, func(i, j int) bool {
+		a, b := articles[i], articles[j]
+		if (a.DeliveredAt == nil) != (b.DeliveredAt == nil) {
+			return a.DeliveredAt == nil
+		}
+		if a.Pinned != b.Pinned {
+			return a.Pinned
+		}
+		if (a.QueuePosition == nil) != (b.QueuePosition == nil) {
+			return a.QueuePosition != nil
+		}
+		if a.QueuePosition != nil && *a.QueuePosition != *b.QueuePosition {
+			return *a.QueuePosition < *b.QueuePosition
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.ArticleID > b.ArticleID
+	})
+}
+
+// SortArticlesByCreated 登録順（記事IDの小さい順）に並べ替える
+func SortArticlesByCreated(articles []models.Article) {
+	sort.SliceStable(articles, func(i, j int) bool {
+		return articles[i].ArticleID < articles[j].ArticleID
+	})
+}
+
+// normalizeText 照合用に全角英数字を半角にし、小文字にそろえる
+func normalizeText(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r >= '！' && r <= '～' {
+			return r - 0xFEE0
+		}
+		if r == '　' {
+			return ' '
+		}
+		return r
+	}, s)
+	return strings.ToLower(s)
+}
+
+// containsTerm textにtermが含まれるかどうか
+// 英数字で始まる・終わる語は前後が英数字でない場合のみ一致とする（"Go" が "Google" に一致しないように）
+func containsTerm(text, term string) bool {
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], term)
+		if i < 0 {
+			return false
+		}
+		start := offset + i
+		end := start + len(term)
+		if boundaryOK(text, start, end, term) {
+			return true
+		}
+		offset = start + 1
+	}
+	return false
+}
+
+// boundaryOK 一致箇所の前後が語の境界になっているかどうか
+func boundaryOK(text string, start, end int, term string) bool {
+	if isASCIIAlnum(rune(term[0])) && start > 0 && isASCIIAlnum(rune(text[start-1])) {
+		return false
+	}
+	if isASCIIAlnum(rune(term[len(term)-1])) && end < len(text) && isASCIIAlnum(rune(text[end])) {
+		return false
+	}
+	return true
+}
+
+func isASCIIAlnum(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
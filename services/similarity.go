@@ -0,0 +1,188 @@
+package services
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"unicode"
+
+	"login-app/models"
+)
+
+// 類似記事の判定の設定
+// MinHashの署名をバンドに分けて候補を絞り（LSH）、推定したJaccard係数がしきい値以上のものを類似とする
+const (
+	minHashSize            = 64
+	minHashBands           = 16
+	minHashRows            = minHashSize / minHashBands
+	shingleSize            = 3
+	nearDuplicateThreshold = 0.6
+)
+
+// minHashSeeds 署名の各要素に使うハッシュの種
+var minHashSeeds = func() [minHashSize]uint64 {
+	var seeds [minHashSize]uint64
+	x := uint64(0x9E3779B97F4A7C15)
+	for i := range seeds {
+		// splitmix64
+		x += 0x9E3779B97F4A7C15
+		z := x
+		z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+		z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+		seeds[i] = z ^ (z >> 31)
+	}
+	return seeds
+}()
+
+// NearDuplicateCluster 内容が類似した記事のまとまり
+// Representativeはまとまりを代表する記事（スコアが最も高い記事）
+type NearDuplicateCluster struct {
+	Representative int64
+	Members        []int64
+}
+
+// FindNearDuplicates タイトルと要約が類似した記事をまとめる（2件以上のまとまりだけを返す）
+// 日本語にも対応できるよう、単語ではなく文字のn-gramで比較する
+func FindNearDuplicates(articles []models.Article) []NearDuplicateCluster {
+	signatures := make([]*[minHashSize]uint64, len(articles))
+	for i, article := range articles {
+		content := models.ParseArticleContent(article.Content)
+		signatures[i] = minHashSignature(shingles(content.Title + " " + content.Summary))
+	}
+
+	// 同じバンドの値を持つ記事を候補として比較する
+	parent := make([]int, len(articles))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	compared := make(map[[2]int]bool)
+	for band := 0; band < minHashBands; band++ {
+		buckets := make(map[uint64][]int)
+		for i, sig := range signatures {
+			if sig == nil {
+				continue
+			}
+			h := fnv.New64a()
+			for r := 0; r < minHashRows; r++ {
+				v := sig[band*minHashRows+r]
+				for b := 0; b < 8; b++ {
+					h.Write([]byte{byte(v >> (8 * b))})
+				}
+			}
+			key := h.Sum64()
+			buckets[key] = append(buckets[key], i)
+		}
+
+		for _, members := range buckets {
+			for x := 0; x < len(members); x++ {
+				for y := x + 1; y < len(members); y++ {
+					pair := [2]int{members[x], members[y]}
+					if compared[pair] {
+						continue
+					}
+					compared[pair] = true
+					if estimateJaccard(signatures[pair[0]], signatures[pair[1]]) >= nearDuplicateThreshold {
+						parent[find(pair[0])] = find(pair[1])
+					}
+				}
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	for i := range articles {
+		if signatures[i] != nil {
+			root := find(i)
+			groups[root] = append(groups[root], i)
+		}
+	}
+
+	var clusters []NearDuplicateCluster
+	for _, members := range groups {
+		if len(members) < 2 {
+			continue
+		}
+		// スコアの高い順（同点の場合は古い記事を先）に並べ、先頭を代表にする
+		sort.Slice(members, func(x, y int) bool {
+			a, b := articles[members[x]], articles[members[y]]
+			if a.Score != b.Score {
+				return a.Score > b.Score
+			}
+			return a.ArticleID < b.ArticleID
+		})
+		cluster := NearDuplicateCluster{Representative: articles[members[0]].ArticleID}
+		for _, i := range members {
+			cluster.Members = append(cluster.Members, articles[i].ArticleID)
+		}
+		clusters = append(clusters, cluster)
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].Representative < clusters[j].Representative })
+	return clusters
+}
+
+// shingles 照合用に正規化した文字列から、記号と空白を除いた文字のn-gramを作る
+func shingles(text string) map[string]bool {
+	var runes []rune
+	for _, r := range normalizeText(text) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			runes = append(runes, r)
+		}
+	}
+
+	result := make(map[string]bool)
+	if len(runes) < shingleSize {
+		if len(runes) > 0 {
+			result[string(runes)] = true
+		}
+		return result
+	}
+	for i := 0; i+shingleSize <= len(runes); i++ {
+		result[string(runes[i:i+shingleSize])] = true
+	}
+	return result
+}
+
+// minHashSignature n-gramの集合のMinHash署名（集合が空の場合はnil）
+func minHashSignature(set map[string]bool) *[minHashSize]uint64 {
+	if len(set) == 0 {
+		return nil
+	}
+
+	var sig [minHashSize]uint64
+	for i := range sig {
+		sig[i] = math.MaxUint64
+	}
+	for s := range set {
+		h := fnv.New64a()
+		h.Write([]byte(s))
+		base := h.Sum64()
+		for i, seed := range minHashSeeds {
+			// 種ごとに値を混ぜて別々のハッシュ関数として扱う
+			v := (base ^ seed) * 0xFF51AFD7ED558CCD
+			v ^= v >> 33
+			if v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+	return &sig
+}
+
+// estimateJaccard 署名の一致した割合からJaccard係数を推定する
+func estimateJaccard(a, b *[minHashSize]uint64) float64 {
+	same := 0
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	return float64(same) / minHashSize
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"login-app/models"
+)
+
+// 統計の集計期間（週数）
+const (
+	DefaultStatsWeeks = 12
+	MaxStatsWeeks     = 52
+	mostEngagedFields = 5
+)
+
+// WeeklyStats 1週間（月曜始まり）ごとの件数
+type WeeklyStats struct {
+	WeekStart string `json:"week_start"` // "2006-01-02"
+	Reserved  int    `json:"reserved"`
+	Delivered int    `json:"delivered"`
+	Clicks    int    `json:"clicks"`
+}
+
+// RoomStats ルームの週ごとの推移
+type RoomStats struct {
+	TimeZone string        `json:"timezone"`
+	Weeks    []WeeklyStats `json:"weeks"`
+	Totals   WeeklyStats   `json:"totals"`
+}
+
+// FieldStats 分野ごとの集計
+type FieldStats struct {
+	FieldFeedback
+	Queued    int `json:"queued"`    // 一致する未配信の予約記事の件数
+	Delivered int `json:"delivered"` // 期間内に配信した一致する記事の件数
+}
+
+// FieldStatsReport 分野ごとの集計と、反応の多い分野・一致する記事のない分野
+type FieldStatsReport struct {
+	Weeks       int          `json:"weeks"`
+	Fields      []FieldStats `json:"fields"`
+	MostEngaged []FieldStats `json:"most_engaged"`
+	StaleFields []string     `json:"stale_fields"`
+}
+
+// BucketWeekly 時刻の一覧を週ごとに数える
+// 週はlocでの月曜0時から始まり、nowを含む週までの直近weeks週を古い順に返す
+func BucketWeekly(now time.Time, loc *time.Location, weeks int, reserved, delivered, clicks []time.Time) []WeeklyStats {
+	current := weekStart(now.In(loc))
+	first := current.AddDate(0, 0, -7*(weeks-1))
+
+	series := make([]WeeklyStats, weeks)
+	for i := range series {
+		series[i].WeekStart = first.AddDate(0, 0, 7*i).Format("2006-01-02")
+	}
+	count := func(times []time.Time, inc func(*WeeklyStats)) {
+		for _, t := range times {
+			start := weekStart(t.In(loc))
+			if start.Before(first) || start.After(current) {
+				continue
+			}
+			// 夏時間で1日の長さが変わっても週の番号がずれないよう、日付で数える
+			i := int(start.Sub(first).Hours()+12) / (24 * 7)
+			inc(&series[i])
+		}
+	}
+	count(reserved, func(w *WeeklyStats) { w.Reserved++ })
+	count(delivered, func(w *WeeklyStats) { w.Delivered++ })
+	count(clicks, func(w *WeeklyStats) { w.Clicks++ })
+	return series
+}
+
+// weekStart tを含む週の月曜0時
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	y, m, d := t.Date()
+	return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+}
+
+// BuildRoomStats ルームの予約・配信・クリックの件数を週ごとに集計する
+// 週の区切りはルームの配信設定のタイムゾーンで決める
+func BuildRoomStats(roomID string, weeks int, now time.Time) (*RoomStats, error) {
+	loc, name, err := roomLocation(roomID)
+	if err != nil {
+		return nil, err
+	}
+	since := weekStart(now.In(loc)).AddDate(0, 0, -7*(weeks-1))
+
+	articles, err := models.FetchArticles(roomID)
+	if err != nil {
+		return nil, err
+	}
+	histories, err := models.FetchHistorySince(roomID, since)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := models.FetchDeliveredLogs(roomID, since)
+	if err != nil {
+		return nil, err
+	}
+	clicks, err := models.FetchArticleClicks(roomID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	// 予約時刻は予約中の記事と、配信・削除されて履歴だけに残る記事から重複なく集める
+	reservedAt := make(map[int64]time.Time)
+	for _, h := range histories {
+		if h.ReservedAt != nil {
+			reservedAt[h.ArticleID] = *h.ReservedAt
+		}
+	}
+	for _, a := range articles {
+		if a.CreatedAt != nil {
+			reservedAt[a.ArticleID] = *a.CreatedAt
+		}
+	}
+	reservedTimes := make([]time.Time, 0, len(reservedAt))
+	for _, t := range reservedAt {
+		reservedTimes = append(reservedTimes, t)
+	}
+	deliveredTimes := make([]time.Time, 0, len(logs))
+	for _, l := range logs {
+		deliveredTimes = append(deliveredTimes, l.AttemptedAt)
+	}
+	clickTimes := make([]time.Time, 0, len(clicks))
+	for _, c := range clicks {
+		clickTimes = append(clickTimes, c.ClickedAt)
+	}
+
+	stats := &RoomStats{
+		TimeZone: name,
+		Weeks:    BucketWeekly(now, loc, weeks, reservedTimes, deliveredTimes, clickTimes),
+	}
+	for _, w := range stats.Weeks {
+		stats.Totals.Reserved += w.Reserved
+		stats.Totals.Delivered += w.Delivered
+		stats.Totals.Clicks += w.Clicks
+	}
+	return stats, nil
+}
+
+// BuildFieldStats ルームの分野ごとに、一致した記事の件数・配信件数・反応を直近weeks週で集計する
+// 反応率の高い分野と、予約中の記事にも期間内の履歴にも一致する記事がない分野をあわせて返す
+func BuildFieldStats(roomID string, weeks int, now time.Time) (*FieldStatsReport, error) {
+	since := now.AddDate(0, 0, -7*weeks)
+
+	fields, err := models.FetchFields(roomID)
+	if err != nil {
+		return nil, err
+	}
+	articles, err := models.FetchArticles(roomID)
+	if err != nil {
+		return nil, err
+	}
+	histories, err := models.FetchHistorySince(roomID, since)
+	if err != nil {
+		return nil, err
+	}
+	feedback, err := models.FetchArticleFeedback(roomID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	queued := make(map[string]int)
+	delivered := make(map[string]int)
+	matches := make([]models.ArticleMatch, 0, len(articles)+len(histories))
+	for _, a := range articles {
+		matches = append(matches, models.ArticleMatch{ArticleID: a.ArticleID, MatchedFields: a.MatchedFields})
+		if a.DeliveredAt == nil {
+			for _, name := range a.MatchedFields {
+				queued[name]++
+			}
+		}
+	}
+	for _, h := range histories {
+		matches = append(matches, models.ArticleMatch{ArticleID: h.ArticleID, MatchedFields: h.MatchedFields})
+		if h.DeliveredAt != nil && !h.DeliveredAt.Before(since) {
+			for _, name := range h.MatchedFields {
+				delivered[name]++
+			}
+		}
+	}
+
+	report := &FieldStatsReport{
+		Weeks:       weeks,
+		Fields:      []FieldStats{},
+		MostEngaged: []FieldStats{},
+		StaleFields: []string{},
+	}
+	for _, f := range AggregateFeedback(fields, matches, feedback) {
+		stat := FieldStats{FieldFeedback: f, Queued: queued[f.FieldName], Delivered: delivered[f.FieldName]}
+		report.Fields = append(report.Fields, stat)
+		if f.Articles == 0 {
+			report.StaleFields = append(report.StaleFields, f.FieldName)
+		}
+	}
+
+	for _, stat := range report.Fields {
+		if stat.Up+stat.Down+stat.Clicks > 0 && stat.Engagement > 0 {
+			report.MostEngaged = append(report.MostEngaged, stat)
+		}
+	}
+	sort.SliceStable(report.MostEngaged, func(i, j int) bool {
+		return report.MostEngaged[i].Engagement > report.MostEngaged[j].Engagement
+	})
+	if len(report.MostEngaged) > mostEngagedFields {
+		report.MostEngaged = report.MostEngaged[:mostEngagedFields]
+	}
+	sort.Strings(report.StaleFields)
+	return report, nil
+}
+
+// roomLocation ルームの配信設定のタイムゾーン（未設定の場合は既定のタイムゾーン）
+func roomLocation(roomID string) (*time.Location, string, error) {
+	name := DefaultDeliveryTimeZone
+	settings, err := models.FetchRoomSettings(roomID)
+	if err != nil {
+		return nil, "", err
+	}
+	if settings != nil && settings.TimeZone != "" {
+		name = settings.TimeZone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, "", err
+	}
+	return loc, name, nil
+}
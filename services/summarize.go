@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"login-app/models"
+	"login-app/summarizer"
+)
+
+// ArticleSummarizer 記事のページ本文から要約を作って記事に保存する
+type ArticleSummarizer struct {
+	summarizer summarizer.Summarizer
+	pages      *MetadataFetcher
+}
+
+// NewArticleSummarizer 要約の処理を作成
+func NewArticleSummarizer(s summarizer.Summarizer, pages *MetadataFetcher) *ArticleSummarizer {
+	return &ArticleSummarizer{summarizer: s, pages: pages}
+}
+
+// Summarize 記事URLのページ本文を要約し、記事の要約を置き換えて保存する
+// ページを取得できない場合は、記事に保存されているタイトルと要約をもとに要約し直す
+func (a *ArticleSummarizer) Summarize(ctx context.Context, article *models.Article) (string, error) {
+	content := models.ParseArticleContent(article.Content)
+	if content.URL == "" {
+		return "", fmt.Errorf("記事のURLがありません")
+	}
+
+	text, err := a.pages.FetchText(ctx, content.URL)
+	if err != nil {
+		fmt.Printf("本文の取得エラー - URL: %s, Error: %v\n", content.URL, err)
+	}
+	if strings.TrimSpace(text) == "" {
+		text = content.Summary
+	}
+
+	summary, err := a.summarizer.Summarize(ctx, summarizer.Document{Title: content.Title, Text: text})
+	if err != nil {
+		return "", err
+	}
+
+	content.Summary = summary
+	rendered := models.RenderArticleContent(content)
+	if err := models.UpdateArticle(article.ArticleID, map[string]interface{}{"content": rendered}); err != nil {
+		return "", err
+	}
+	article.Content = rendered
+	if err := RescoreArticle(article); err != nil {
+		fmt.Printf("記事の再採点エラー - Article ID: %d, Error: %v\n", article.ArticleID, err)
+	}
+	return summary, nil
+}
+
+// SummarizeText 取り込み時など、保存前の本文を要約する
+func (a *ArticleSummarizer) SummarizeText(ctx context.Context, title, text string) (string, error) {
+	return a.summarizer.Summarize(ctx, summarizer.Document{Title: title, Text: text})
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeWrite fakeSupabaseが受け取った書き込みのリクエスト
+type fakeWrite struct {
+	Method string
+	Table  string
+	Query  string
+	Body   interface{}
+}
+
+// fakeSupabase Supabase REST APIを模したhttp.Handler
+// GETにはテーブルごとに設定した行を返し（絞り込みは行わない）、それ以外のリクエストは記録して
+// return=representationの書き込みには受け取った行をそのまま返す（Respondで変更できる）
+type fakeSupabase struct {
+	mu        sync.Mutex
+	tables    map[string]interface{}
+	responses map[string]interface{}
+	writes    []fakeWrite
+}
+
+// newFakeSupabase fakeSupabaseを起動し、SUPABASE_URLをその向き先にする
+func newFakeSupabase(t *testing.T) *fakeSupabase {
+	t.Helper()
+	f := &fakeSupabase{tables: make(map[string]interface{}), responses: make(map[string]interface{})}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	t.Setenv("SUPABASE_URL", srv.URL)
+	t.Setenv("SUPABASE_KEY", "test-key")
+	return f
+}
+
+// Set GETでtableに返す行を設定する
+func (f *fakeSupabase) Set(table string, rows interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tables[table] = rows
+}
+
+// Respond methodでtableに書き込んだときにreturn=representationで返す行を設定する
+func (f *fakeSupabase) Respond(method, table string, rows interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[method+" "+table] = rows
+}
+
+// Writes これまでに受け取ったtableへの書き込み（tableが空の場合はすべて）
+func (f *fakeSupabase) Writes(table string) []fakeWrite {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var writes []fakeWrite
+	for _, w := range f.writes {
+		if table == "" || w.Table == table {
+			writes = append(writes, w)
+		}
+	}
+	return writes
+}
+
+func (f *fakeSupabase) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	table := strings.TrimPrefix(r.URL.Path, "/rest/v1/")
+	w.Header().Set("Content-Type", "application/json")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Method == http.MethodGet {
+		rows, ok := f.tables[table]
+		if !ok {
+			rows = []interface{}{}
+		}
+		json.NewEncoder(w).Encode(rows)
+		return
+	}
+
+	var body interface{}
+	json.NewDecoder(r.Body).Decode(&body)
+	f.writes = append(f.writes, fakeWrite{Method: r.Method, Table: table, Query: r.URL.RawQuery, Body: body})
+	if strings.Contains(r.Header.Get("Prefer"), "return=representation") {
+		if rows, ok := f.responses[r.Method+" "+table]; ok {
+			json.NewEncoder(w).Encode(rows)
+		} else {
+			json.NewEncoder(w).Encode([]interface{}{body})
+		}
+		return
+	}
+	w.Write([]byte("[]"))
+}
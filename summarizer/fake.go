@@ -0,0 +1,83 @@
+package summarizer
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// FakeLLMServer Chat Completions APIだけを模したhttp.Handler
+// 本文の最初の文を要約として返す。テストではhttptest.NewServer(fake)で起動し、そのURLをNewLLMClientに渡して使う
+type FakeLLMServer struct {
+	mu         sync.Mutex
+	requests   int
+	failures   int
+	failStatus int
+}
+
+// NewFakeLLMServer FakeLLMServerを作成
+func NewFakeLLMServer() *FakeLLMServer {
+	return &FakeLLMServer{}
+}
+
+// FailNext 次のn回のリクエストをstatusで失敗させる
+func (f *FakeLLMServer) FailNext(n, status int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = n
+	f.failStatus = status
+}
+
+// Requests これまでに受け取った要約のリクエスト数
+func (f *FakeLLMServer) Requests() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests
+}
+
+// ServeHTTP POST /chat/completions を処理する
+func (f *FakeLLMServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/chat/completions") {
+		writeFakeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	var req chatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Messages) == 0 {
+		writeFakeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	f.mu.Lock()
+	f.requests++
+	if f.failures > 0 {
+		f.failures--
+		status := f.failStatus
+		f.mu.Unlock()
+		writeFakeError(w, status, "fake failure")
+		return
+	}
+	f.mu.Unlock()
+
+	// 本文の最初の文を要約として返す
+	content := req.Messages[len(req.Messages)-1].Content
+	if i := strings.Index(content, "本文:\n"); i >= 0 {
+		content = content[i+len("本文:\n"):]
+	}
+	summary := "要約できる本文がありません"
+	if sentences := SplitSentences(content); len(sentences) > 0 {
+		summary = sentences[0]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(chatResponse{Choices: []struct {
+		Message chatMessage `json:"message"`
+	}{{Message: chatMessage{Role: "assistant", Content: summary}}}})
+}
+
+func writeFakeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]map[string]string{"error": {"message": message}})
+}
@@ -0,0 +1,122 @@
+package summarizer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// LLMの呼び出しの設定
+const (
+	maxLLMInputLength = 8000
+	llmMaxTokens      = 400
+	llmSystemPrompt   = "あなたはニュース記事の要約者です。記事の要点を日本語で3文以内にまとめてください。前置きや見出しは付けず、要約の文だけを返してください。"
+)
+
+// APIError LLMのAPIがエラーを返した場合のエラー
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("LLM APIエラー - Status: %d, Body: %s", e.StatusCode, e.Body)
+}
+
+// LLMClient OpenAI互換のChat Completions APIで要約するSummarizerの実装
+// テストやローカル開発ではFakeLLMServerに向けて使う
+type LLMClient struct {
+	endpoint   string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+// NewLLMClient LLMのクライアントを作成
+// baseURLには /chat/completions を除いたURL（例: https://api.openai.com/v1）を指定する
+func NewLLMClient(baseURL, apiKey, model string) *LLMClient {
+	return &LLMClient{
+		endpoint:   strings.TrimSuffix(baseURL, "/") + "/chat/completions",
+		apiKey:     apiKey,
+		model:      model,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model     string        `json:"model"`
+	Messages  []chatMessage `json:"messages"`
+	MaxTokens int           `json:"max_tokens"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// Summarize 記事のタイトルと本文をLLMに渡して要約を作る
+func (c *LLMClient) Summarize(ctx context.Context, doc Document) (string, error) {
+	text := strings.TrimSpace(doc.Text)
+	if text == "" {
+		return "", ErrEmptyDocument
+	}
+	if utf8.RuneCountInString(text) > maxLLMInputLength {
+		text = string([]rune(text)[:maxLLMInputLength])
+	}
+
+	payload, err := json.Marshal(chatRequest{
+		Model: c.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: llmSystemPrompt},
+			{Role: "user", Content: fmt.Sprintf("タイトル: %s\n\n本文:\n%s", doc.Title, text)},
+		},
+		MaxTokens: llmMaxTokens,
+	})
+	if err != nil {
+		return "", fmt.Errorf("JSONの作成に失敗しました: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("リクエストの作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("APIリクエストに失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("レスポンスの読み取りに失敗しました: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	var result chatResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("JSONのパースに失敗しました: %w", err)
+	}
+	if len(result.Choices) == 0 || strings.TrimSpace(result.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("要約が返されませんでした")
+	}
+	return strings.TrimSpace(result.Choices[0].Message.Content), nil
+}
@@ -0,0 +1,123 @@
+package summarizer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLLMClientSummarize(t *testing.T) {
+	fake := NewFakeLLMServer()
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	client := NewLLMClient(srv.URL+"/", "test-key", "test-model")
+	summary, err := client.Summarize(context.Background(), Document{
+		Title: "新製品",
+		Text:  "新しい製品が発表されました。価格は未定です。",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary != "新しい製品が発表されました。" {
+		t.Errorf("summary = %q", summary)
+	}
+	if fake.Requests() != 1 {
+		t.Errorf("requests = %d", fake.Requests())
+	}
+}
+
+func TestLLMClientEmptyDocument(t *testing.T) {
+	fake := NewFakeLLMServer()
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	client := NewLLMClient(srv.URL, "", "test-model")
+	if _, err := client.Summarize(context.Background(), Document{Title: "タイトル", Text: " \n "}); !errors.Is(err, ErrEmptyDocument) {
+		t.Errorf("本文がなければErrEmptyDocument: %v", err)
+	}
+	if fake.Requests() != 0 {
+		t.Errorf("本文がなければAPIを呼ばない: %d", fake.Requests())
+	}
+}
+
+func TestLLMClientAPIError(t *testing.T) {
+	fake := NewFakeLLMServer()
+	fake.FailNext(1, http.StatusTooManyRequests)
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	client := NewLLMClient(srv.URL, "test-key", "test-model")
+	_, err := client.Summarize(context.Background(), Document{Text: "本文です。"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("APIErrorを返す: %v", err)
+	}
+
+	// 失敗は指定した回数だけ
+	if _, err := client.Summarize(context.Background(), Document{Text: "本文です。"}); err != nil {
+		t.Errorf("2回目は成功する: %v", err)
+	}
+}
+
+func TestLLMClientTimeout(t *testing.T) {
+	fake := NewFakeLLMServer()
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	client := NewLLMClient(srv.URL, "test-key", "test-model")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.Summarize(ctx, Document{Text: "本文です。"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("期限を過ぎたらエラーにする: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("期限で打ち切る: %v", elapsed)
+	}
+}
+
+func TestWithFallbackUsesExtractiveWhenLLMFails(t *testing.T) {
+	fake := NewFakeLLMServer()
+	fake.FailNext(1, http.StatusInternalServerError)
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	s := WithFallback(NewLLMClient(srv.URL, "test-key", "test-model"), NewExtractive(1))
+	doc := Document{Text: "最初の文です。"}
+
+	summary, err := s.Summarize(context.Background(), doc)
+	if err != nil || summary != "最初の文です。" {
+		t.Errorf("LLMが失敗したら抽出型で要約する: %q, %v", summary, err)
+	}
+	if fake.Requests() != 1 {
+		t.Errorf("requests = %d", fake.Requests())
+	}
+
+	// LLMが成功した場合はfallbackを使わない
+	s = WithFallback(NewLLMClient(srv.URL, "test-key", "test-model"), failingSummarizer{})
+	if summary, err := s.Summarize(context.Background(), doc); err != nil || summary != "最初の文です。" {
+		t.Errorf("LLMの要約を返す: %q, %v", summary, err)
+	}
+}
+
+// failingSummarizer 常に失敗するSummarizer
+type failingSummarizer struct{}
+
+func (failingSummarizer) Summarize(ctx context.Context, doc Document) (string, error) {
+	return "", errors.New("呼ばれないはず")
+}
@@ -0,0 +1,219 @@
+package summarizer
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Document 要約する記事
+type Document struct {
+	Title string
+	Text  string
+}
+
+// Summarizer 記事の本文から要約を作る
+// 本番では外部のLLMを使うLLMClientか、外部サービスを使わないExtractiveを使う
+type Summarizer interface {
+	Summarize(ctx context.Context, doc Document) (string, error)
+}
+
+// ErrEmptyDocument 要約できる本文がない場合のエラー
+var ErrEmptyDocument = errors.New("要約する本文がありません")
+
+// 抽出型要約の設定
+const (
+	DefaultSummarySentences = 3
+	minSentenceLength       = 10
+	maxSentenceLength       = 300
+)
+
+// englishStopWords 重要度の計算から除く英語の語
+var englishStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "was": true, "were": true, "with": true,
+	"that": true, "this": true, "from": true, "have": true, "has": true, "had": true, "its": true,
+	"into": true, "but": true, "not": true, "you": true, "your": true, "our": true, "their": true,
+	"they": true, "will": true, "can": true, "also": true, "been": true, "which": true, "about": true,
+	"more": true, "than": true, "such": true, "these": true, "those": true, "there": true, "what": true,
+}
+
+// Extractive 本文中の文を採点し、重要な文を抜き出して要約にする
+// 英語は単語、日本語などの分かち書きしない文字は2文字ずつの組み合わせを語として扱う
+type Extractive struct {
+	MaxSentences int
+}
+
+// NewExtractive 抽出型の要約を作成（maxSentencesが0以下の場合は既定の文数）
+func NewExtractive(maxSentences int) *Extractive {
+	if maxSentences <= 0 {
+		maxSentences = DefaultSummarySentences
+	}
+	return &Extractive{MaxSentences: maxSentences}
+}
+
+// Summarize 語の出現頻度・タイトルとの重なり・文の位置で採点し、上位の文を本文の順に並べて返す
+func (e *Extractive) Summarize(ctx context.Context, doc Document) (string, error) {
+	sentences := SplitSentences(doc.Text)
+	if len(sentences) == 0 {
+		return "", ErrEmptyDocument
+	}
+	if len(sentences) <= e.MaxSentences {
+		return strings.Join(sentences, "\n"), nil
+	}
+
+	// 本文全体での語の出現回数
+	tokenized := make([][]string, len(sentences))
+	freq := make(map[string]int)
+	for i, s := range sentences {
+		tokenized[i] = Tokenize(s)
+		for _, t := range tokenized[i] {
+			freq[t]++
+		}
+	}
+	maxFreq := 1
+	for _, n := range freq {
+		if n > maxFreq {
+			maxFreq = n
+		}
+	}
+	titleTokens := make(map[string]bool)
+	for _, t := range Tokenize(doc.Title) {
+		titleTokens[t] = true
+	}
+
+	type scored struct {
+		index int
+		score float64
+	}
+	candidates := make([]scored, 0, len(sentences))
+	for i, tokens := range tokenized {
+		length := len([]rune(sentences[i]))
+		if len(tokens) == 0 || length < minSentenceLength || length > maxSentenceLength {
+			continue
+		}
+
+		score := 0.0
+		for _, t := range tokens {
+			score += float64(freq[t]) / float64(maxFreq)
+			if titleTokens[t] {
+				score += 1.0
+			}
+		}
+		// 長い文ばかり選ばれないように語数で割り、冒頭の文を少し優先する
+		score /= math.Sqrt(float64(len(tokens)))
+		score *= 1.0 + 0.5/float64(i+1)
+		candidates = append(candidates, scored{index: i, score: score})
+	}
+	if len(candidates) == 0 {
+		return strings.Join(sentences[:e.MaxSentences], "\n"), nil
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+	if len(candidates) > e.MaxSentences {
+		candidates = candidates[:e.MaxSentences]
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].index < candidates[j].index })
+
+	picked := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		picked = append(picked, sentences[c.index])
+	}
+	return strings.Join(picked, "\n"), nil
+}
+
+// SplitSentences 本文を文に分ける
+// 「。」「！」「？」と改行、英語の文末の「.」「!」「?」（直後が空白の場合）で区切る
+func SplitSentences(text string) []string {
+	var sentences []string
+	var current []rune
+	flush := func() {
+		if s := strings.Join(strings.Fields(string(current)), " "); s != "" {
+			sentences = append(sentences, s)
+		}
+		current = current[:0]
+	}
+
+	runes := []rune(text)
+	for i, r := range runes {
+		if r == '\n' || r == '\r' {
+			flush()
+			continue
+		}
+		current = append(current, r)
+		switch r {
+		case '。', '！', '？':
+			flush()
+		case '.', '!', '?':
+			if i+1 == len(runes) || unicode.IsSpace(runes[i+1]) {
+				flush()
+			}
+		}
+	}
+	flush()
+	return sentences
+}
+
+// Tokenize 採点用に文を語に分ける
+// 英数字は小文字にした単語（2文字以上・一般的な語を除く）、それ以外の文字は連続する2文字の組にする
+func Tokenize(text string) []string {
+	var tokens []string
+	var word []rune
+	var cjk []rune
+
+	flushWord := func() {
+		if len(word) >= 2 {
+			w := strings.ToLower(string(word))
+			if !englishStopWords[w] {
+				tokens = append(tokens, w)
+			}
+		}
+		word = word[:0]
+	}
+	flushCJK := func() {
+		if len(cjk) == 1 {
+			tokens = append(tokens, string(cjk))
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			tokens = append(tokens, string(cjk[i:i+2]))
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			flushCJK()
+			word = append(word, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushWord()
+			cjk = append(cjk, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return tokens
+}
+
+// WithFallback primaryで要約できなかった場合にfallbackを使うSummarizer
+func WithFallback(primary, fallback Summarizer) Summarizer {
+	return &fallbackSummarizer{primary: primary, fallback: fallback}
+}
+
+type fallbackSummarizer struct {
+	primary  Summarizer
+	fallback Summarizer
+}
+
+func (f *fallbackSummarizer) Summarize(ctx context.Context, doc Document) (string, error) {
+	summary, err := f.primary.Summarize(ctx, doc)
+	if err == nil && strings.TrimSpace(summary) != "" {
+		return summary, nil
+	}
+	return f.fallback.Summarize(ctx, doc)
+}
@@ -0,0 +1,54 @@
+package summarizer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitSentences(t *testing.T) {
+	got := SplitSentences("一つ目の文。二つ目！\nThird one. v1.2 is out?  最後")
+	want := []string{"一つ目の文。", "二つ目！", "Third one.", "v1.2 is out?", "最後"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitSentences = %q, want %q", got, want)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("The Go言語 and API")
+	want := []string{"go", "言語", "api"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokenize = %q, want %q", got, want)
+	}
+}
+
+func TestExtractiveSummarize(t *testing.T) {
+	e := NewExtractive(2)
+
+	if _, err := e.Summarize(context.Background(), Document{Text: "   "}); !errors.Is(err, ErrEmptyDocument) {
+		t.Errorf("本文がなければErrEmptyDocument: %v", err)
+	}
+
+	short := Document{Text: "短い本文です。もう一文あります。"}
+	if got, _ := e.Summarize(context.Background(), short); got != "短い本文です。\nもう一文あります。" {
+		t.Errorf("文数が上限以下ならそのまま返す: %q", got)
+	}
+
+	doc := Document{
+		Title: "新しい検索エンジンを公開",
+		Text: "本日、新しい検索エンジンを公開しました。" +
+			"天気は晴れで気温は二十度でした。" +
+			"検索エンジンは記事の検索を高速にします。" +
+			"社員食堂のメニューが変わりました。",
+	}
+	got, err := e.Summarize(context.Background(), doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sentences := strings.Split(got, "\n")
+	if len(sentences) != 2 || !strings.Contains(sentences[0], "公開") || !strings.Contains(sentences[1], "高速") {
+		t.Errorf("タイトルに関係する文を本文の順に選ぶ: %q", got)
+	}
+}